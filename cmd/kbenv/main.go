@@ -7,14 +7,9 @@ import (
 	"github.com/mitchellh/go-homedir"
 )
 
-var binaryDownloadURL string = "https://dl.k8s.io/release/v%s/bin/%s/%s/kubectl"                          // nolint:lll
-var versionsAPI string = "https://api.github.com/repos/kubernetes/kubernetes/releases?per_page=100&page=" // nolint:lll
-
 func main() {
 	home, _ := homedir.Dir()
 	_ = os.MkdirAll(home+"/.bin", os.ModePerm)
-	cmd.BinaryDownloadURL = binaryDownloadURL
-	cmd.VersionsAPI = versionsAPI
 	cmd.BinaryToInstall = "kubectl"
 	cmd.RootCmd.Use = "kbenv"
 	cmd.RootCmd.Short = "Kubectl version manager"
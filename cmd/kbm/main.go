@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/cmd"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/provider"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/wrapper"
+	"github.com/mitchellh/go-homedir"
+)
+
+// managers are the version managers kbm replaces. Invoked through a link with
+// one of these names, kbm behaves as the manager of its tool.
+var managers = map[string]string{
+	"kbenv":   "kubectl",
+	"helmenv": "helm",
+	"ocenv":   "oc",
+}
+
+var descriptions = map[string]string{
+	"kubectl": "Kubectl version manager",
+	"helm":    "Helm version manager",
+	"oc":      "OC version manager",
+}
+
+// kbm manages every tool from one binary, taking the tool as its first
+// argument: kbm kubectl install 1.30.1. Invoked through a link named as a
+// tool, like kubectl or kubectl-wrapper, it runs the selected version of the
+// tool instead. Any other name prints the usage.
+func main() {
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+
+	if tool, ok := managers[name]; ok {
+		manage(tool, name, os.Args[1:])
+		return
+	}
+
+	if !strings.HasPrefix(name, "kbm") {
+		tool := strings.TrimSuffix(name, "-wrapper")
+
+		if !isTool(tool) {
+			usage()
+			os.Exit(1)
+		}
+
+		// The cluster version is installed with this same binary in auto mode
+		if exe, err := os.Executable(); err == nil {
+			wrapper.Installer = []string{exe, tool}
+		}
+
+		wrapper.Wrapper(tool)
+
+		return
+	}
+
+	if len(os.Args) < 2 || os.Args[1] == "help" || strings.HasPrefix(os.Args[1], "-") { // nolint: mnd
+		usage()
+		return
+	}
+
+	manage(os.Args[1], "kbm "+os.Args[1], os.Args[2:])
+}
+
+func manage(tool string, use string, args []string) {
+	home, _ := homedir.Dir()
+	_ = os.MkdirAll(home+"/.bin", os.ModePerm)
+
+	short, ok := descriptions[tool]
+	if !ok {
+		short = fmt.Sprintf("%s version manager", tool)
+	}
+
+	cmd.BinaryToInstall = tool
+	cmd.RootCmd.Use = use
+	cmd.RootCmd.Short = short
+	cmd.RootCmd.SetArgs(args)
+	cmd.Execute()
+}
+
+// isTool tells if tool is registered, built in or defined in the tools
+// directory.
+func isTool(tool string) bool {
+	logging.Setup("error")
+
+	// A broken definition is reported by the usage
+	_ = provider.LoadDefinitions(provider.DefinitionsDir())
+
+	_, err := provider.Defaults(tool)
+
+	return err == nil
+}
+
+func usage() {
+	logging.Setup("error")
+
+	if err := provider.LoadDefinitions(provider.DefinitionsDir()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Println("Kubernetes binaries manager")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  kbm <tool> [command]")
+	fmt.Println()
+	fmt.Println("Available Tools:")
+
+	for _, name := range provider.Names() {
+		fmt.Printf("  %s\n", name)
+	}
+
+	fmt.Println()
+	fmt.Println(`Use "kbm <tool> --help" for more information about the commands of a tool.`)
+}
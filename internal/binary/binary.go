@@ -2,28 +2,23 @@ package binary
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"io/fs"
-
-	"net/http"
 	"os"
-	"strings"
-
 	"path/filepath"
+	"strings"
 
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/cache"
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
-	. "github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers" // nolint:staticcheck
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/provider"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
 	"github.com/mholt/archives"
 	"github.com/sirupsen/logrus"
 )
 
-const (
-	zip   = ".zip"
-	targz = ".tar.gz"
-	exe   = ".exe"
-)
+const exe = ".exe"
 
 var osArch *helpers.OSArch
 
@@ -48,238 +43,221 @@ func (e *DownloadError) Error() string {
 	return error
 }
 
-func Download(version string, url string) ([]byte, error) { // nolint: funlen
-	var (
-		err  error
-		body []byte
-	)
+// Download returns the path of the artifact of the given version of tool. It
+// comes from the cache when possible, otherwise it's streamed to disk, resuming
+// interrupted downloads when the server supports ranges, verified against the
+// upstream checksum if verify is set and stored in the cache. The artifact is
+// taken from the assets of the release tagged tag when the provider publishes
+// them, and from its url template otherwise.
+func Download(p provider.Provider, version string, tag string, verify bool) (string, error) {
+	if entry, ok := cache.Lookup(p.Name(), version, osArch.OS, osArch.Arch); ok && (entry.Verified || !verify) {
+		logging.Debug("using cached artifact", "path", entry.Path())
+		return entry.Path(), nil
+	}
 
-	// Don't control the error since at this point it should be controlled
+	url := p.DownloadURL(version, osArch)
 
-	if strings.Contains(url, "openshift") {
-		// OpenShift use different naming for macOS
-		if osArch.IsDarwin() {
-			osArch.OS = "mac"
-		} else if osArch.IsWindows() {
-			// They also use zip for Windows
-			url = strings.Replace(url, ".tar.gz", ".zip", 1)
-		}
+	asset, err := releaseAsset(p, tag)
 
-		url = fmt.Sprintf(url, version, osArch.OS, version)
-	} else {
-		url = fmt.Sprintf(url, version, osArch.OS, osArch.Arch)
+	switch {
+	case asset != nil:
+		url = asset.URL
+	case err != nil && url == "":
+		// Only the assets are published, there's no url template to fall back to
+		return "", err
+	case err != nil:
+		logging.Warn("using the url template", "error", err)
 	}
 
-	if strings.Contains(url, "helm") {
-		if osArch.IsWindows() {
-			url += zip
-		} else {
-			url += targz
-		}
-	} else if strings.Contains(url, "kubectl") {
-		if osArch.IsWindows() {
-			url += exe
+	fileName, err := fetch(url)
+	if err != nil {
+		return "", err
+	}
+
+	if verify {
+		if err = Verify(p, version, url, fileName); err != nil {
+			os.Remove(fileName)
+			return "", err
 		}
 	}
 
-	logging.Debug("Downloading binary...", "url", url)
+	return store(p.Name(), version, url, fileName, verify)
+}
 
-	resp, err := http.Get(url) // nolint
-	if err != nil {
-		return body, err
+// releaseAsset looks for the artifact for the platform in the assets of the
+// release of tag. It's nil when the release can't be read, which is logged,
+// and an error listing the published platforms when there's none for this one.
+func releaseAsset(p provider.Provider, tag string) (*versions.Asset, error) {
+	if p.AssetName() == "" || tag == "" {
+		return nil, nil
 	}
 
-	defer resp.Body.Close()
+	// Only GitHub releases have assets
+	if source, err := p.VersionSource(); err != nil {
+		return nil, nil
+	} else if _, ok := source.(*versions.GitHub); !ok {
+		return nil, nil
+	}
 
-	body, err = io.ReadAll(resp.Body)
+	release, err := versions.GetRelease(p.VersionsAPI(), tag)
 	if err != nil {
-		return body, err
+		logging.Debug("could not get the release assets", "tag", tag, "error", err)
+		return nil, nil
 	}
 
-	if resp.StatusCode == http.StatusNotFound {
-		return body, &DownloadError{"binary not found", url, string(body)}
-	} else if resp.StatusCode != http.StatusOK {
-		return body, &DownloadError{"unhandled error", url, string(body)}
+	asset, ok := versions.MatchAsset(release.Assets, osArch, p.AssetName())
+	if !ok {
+		return nil, fmt.Errorf("the %s release has no asset for %s, only for: %s", tag, osArch.String(),
+			strings.Join(versions.Platforms(release.Assets, p.AssetName()), ", "))
 	}
 
-	return body, nil
-}
-
-func Save(fileName string, body []byte) error { // nolint: funlen
-	var err error
-
-	// helm returns a compressed file, so save it somewhere and decompress it
-	if strings.Contains(fileName, "helm") {
-		var fileExt string
+	logging.Debug("using release asset", "name", asset.Name, "url", asset.URL)
 
-		tempDir, err := os.MkdirTemp("", "helm-*")
-		if err != nil {
-			return err
-		}
-		// clean temp dir
-		defer os.RemoveAll(tempDir)
+	return asset, nil
+}
 
-		osArch, err := GetOSArch()
-		if err != nil {
-			return err
-		}
-		file := filepath.Join(tempDir, "helm")
+// DownloadFrom downloads the artifact of tool from an arbitrary url and stores
+// it in the cache as the given version. There's no upstream checksum to verify
+// it against.
+func DownloadFrom(p provider.Provider, version string, url string) (string, error) {
+	fileName, err := fetch(url)
+	if err != nil {
+		return "", err
+	}
 
-		if osArch.IsWindows() {
-			fileExt = zip
-		} else {
-			fileExt = targz
-		}
+	return store(p.Name(), version, url, fileName, false)
+}
 
-		err = os.WriteFile(file+fileExt, body, 0750) // nolint: gosec,mnd
-		if err != nil {
-			return err
-		}
+// fetch streams url to disk and returns the path of the file.
+func fetch(url string) (string, error) {
+	var err error
 
-		err = decompress(file+fileExt, file)
-		if err != nil {
-			return err
-		}
+	fileName := downloadPath(url)
+	partial := fileName + partialSuffix
 
-		path, _ := filepath.Abs(file + fmt.Sprintf("/%s-%s/helm", osArch.OS, osArch.Arch))
+	logging.Debug("Downloading binary...", "url", url, "path", partial)
 
-		if osArch.IsWindows() {
-			path += exe
-		}
+	if err = os.MkdirAll(filepath.Dir(fileName), 0750); err != nil { // nolint: mnd
+		return "", err
+	}
 
-		body, err = os.ReadFile(path)
-		if err != nil {
-			return err
-		}
-	} else if strings.Contains(fileName, "okd") {
-		// oc returns a compressed file, so save it somewhere and decompress it
-		var fileExt string
+	if Connections > 1 {
+		err = downloadParallel(url, partial, Connections)
+		if errors.Is(err, errNoRanges) {
+			logging.Debug("server doesn't support parallel ranges, downloading sequentially")
 
-		tempDir, err := os.MkdirTemp("", "oc-*")
-		if err != nil {
-			return err
+			err = downloadResumable(url, partial)
 		}
-		// clean temp dir
-		defer os.RemoveAll(tempDir)
+	} else {
+		err = downloadResumable(url, partial)
+	}
 
-		logging.Debug("created temp dir", "path", tempDir)
+	if err != nil {
+		return "", err
+	}
 
-		file := filepath.Join(tempDir, "oc")
+	if err = os.Rename(partial, fileName); err != nil {
+		return "", err
+	}
 
-		if osArch.IsWindows() {
-			fileExt = zip
-		} else {
-			fileExt = targz
-		}
-		// Write the Archive to disk
-		logging.Debug("writing archive to disk", "path", file+fileExt)
+	os.Remove(partial + validatorSuffix)
 
-		err = os.WriteFile(file+fileExt, body, 0750) // nolint: gosec,mnd
-		if err != nil {
-			return err
-		}
+	return fileName, nil
+}
 
-		err = decompress(file+fileExt, tempDir)
-		if err != nil {
-			return err
-		}
+func store(tool string, version string, url string, fileName string, verified bool) (string, error) {
+	entry, err := cache.Store(cache.Entry{
+		Tool: tool, Version: version, OS: osArch.OS, Arch: osArch.Arch, URL: url, Verified: verified,
+	}, fileName)
+	if err != nil {
+		return "", err
+	}
 
-		path, _ := filepath.Abs(tempDir + "/oc")
+	return entry.Path(), nil
+}
 
-		if osArch.IsWindows() {
-			path += exe
-		}
+// Save installs the binary contained in download as fileName. When the download
+// is an archive, the binary is the only file extracted from it.
+func Save(p provider.Provider, fileName string, download string) error {
+	fsys, err := archives.FileSystem(context.Background(), download, nil)
+	if err != nil {
+		return err
+	}
 
-		body, err = os.ReadFile(path)
+	// Not an archive, it's the binary itself
+	if _, ok := fsys.(archives.FileFS); ok {
+		src, err := os.Open(download) // nolint: gosec
 		if err != nil {
 			return err
 		}
+		defer src.Close()
+
+		return writeBinary(fileName, src)
 	}
 
-	err = os.WriteFile(fileName, body, 0750) // nolint: gosec,mnd
+	member, err := findMember(fsys, p)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return extract(fsys, member, fileName)
 }
 
-func decompress(file, destination string) error {
-	l := logging.L.WithFields(logrus.Fields{"method": "decompress", "file": file, "destination": destination})
+// findMember returns the path of the binary inside the archive. The layout the
+// provider knows is tried first, then the archive is searched for a file named
+// as the binary, since custom builds may be packaged differently.
+func findMember(fsys fs.FS, p provider.Provider) (string, error) {
+	var (
+		binName = p.Name()
+		member  string
+	)
 
-	fsys, err := archives.FileSystem(context.Background(), file, nil)
-	if err != nil {
-		return err
+	if osArch.IsWindows() {
+		binName += exe
 	}
 
-	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
-		fullDst := filepath.Join(destination, path)
+	if path := p.BinaryPath(osArch); path != "" {
+		if _, err := fs.Stat(fsys, path); err == nil {
+			return path, nil
+		}
+	}
 
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip root
-		if path == "." {
-			l.WithFields(logrus.Fields{"path": path, "name": d.Name()}).Debug("Skipping root")
-			return nil
+		if !d.IsDir() && d.Name() == binName {
+			member = path
+			return fs.SkipAll
 		}
 
-		// Skip .git directories
-		if d.IsDir() && path == ".git" {
-			l.WithFields(
-				logrus.Fields{"path": path, "name": d.Name()},
-			).Debug("Skipping .git directory")
-
-			return fs.SkipDir
-		}
-
-		// No Need to extract directories, just files, but we make the directory, anyway...
-		if d.IsDir() {
-			l.WithFields(logrus.
-				Fields{"path": path, "name": d.Name()},
-			).Debug("Looking in directory")
-
-			err = os.MkdirAll(fullDst, 0750) // nolint: gosec,mnd
-
-			return err
-		}
-
-		l.WithFields(
-			logrus.Fields{"path": path, "name": d.Name()},
-		).Debug("opening file in archive")
-
-		f, err := fsys.Open(path)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
 
-		l.WithFields(
-			logrus.Fields{"path": path, "name": d.Name()},
-		).Debug("reading file in archive")
+	if member == "" {
+		return "", fmt.Errorf("%s not found in the archive", binName)
+	}
 
-		data, err := io.ReadAll(f)
-		if err != nil {
-			return err
-		}
+	return member, nil
+}
 
-		l.WithFields(logrus.Fields{
-			"path": path, "name": d.Name(), "destination": fullDst,
-		}).Debug("writing file to destination")
+// extract streams a single file out of the archive into destination.
+func extract(fsys fs.FS, member, destination string) error {
+	l := logging.L.WithFields(logrus.Fields{"method": "extract", "member": member})
 
-		err = os.WriteFile(fullDst, data, 0750) // nolint: gosec,mnd
-		if err != nil {
-			return err
-		}
+	l.Debug("opening file in archive")
 
-		return nil
-	})
+	f, err := fsys.Open(member)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
-	l.Debug("decompression complete")
+	l.WithField("destination", destination).Debug("writing file to destination")
 
-	return nil
+	return writeBinary(destination, f)
 }
@@ -0,0 +1,362 @@
+package binary
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/config"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func targzArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content))}))
+		_, err := tw.Write(content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	return buf.Bytes()
+}
+
+// testProvider returns the provider of tool downloading from url.
+func testProvider(t *testing.T, tool string, url string) provider.Provider {
+	t.Helper()
+
+	p, err := provider.New(tool, config.Tool{BinaryDownloadURL: url})
+	require.NoError(t, err)
+
+	return p
+}
+
+func TestDownloadAndSave(t *testing.T) {
+	if osArch.IsWindows() {
+		t.Skip("archives are zip files on windows")
+	}
+
+	var flagtests = []struct {
+		testName string
+		tool     string
+		url      string
+		fileName string
+		served   []byte
+		expected []byte
+	}{
+		{
+			"plain binary",
+			"kubectl",
+			"/release/v%s/bin/%s/%s/kubectl",
+			"kubectl-v1.31.0",
+			[]byte("kubectl binary"),
+			[]byte("kubectl binary"),
+		},
+		{
+			"helm archive",
+			"helm",
+			"/helm-v%s-%s-%s",
+			"helm-v3.19.0",
+			targzArchive(t, map[string][]byte{
+				fmt.Sprintf("%s-%s/helm", osArch.OS, osArch.Arch):      []byte("helm binary"),
+				fmt.Sprintf("%s-%s/README.md", osArch.OS, osArch.Arch): []byte("readme"),
+			}),
+			[]byte("helm binary"),
+		},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, err := rw.Write(tt.served)
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+			p := testProvider(t, tt.tool, server.URL+tt.url)
+
+			download, err := Download(p, "1.0.0", "v1.0.0", false)
+			require.NoError(t, err)
+
+			fileName := filepath.Join(t.TempDir(), tt.fileName)
+			require.NoError(t, Save(p, fileName, download))
+
+			actual, err := os.ReadFile(fileName) // nolint: gosec
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestDownloadFromAndSaveCustomArchive(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if osArch.IsWindows() {
+		t.Skip("archives are zip files on windows")
+	}
+
+	// A custom build with a layout different from the upstream one
+	archive := targzArchive(t, map[string][]byte{
+		"release/bin/oc":  []byte("patched oc"),
+		"release/LICENSE": []byte("license"),
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write(archive)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	p := testProvider(t, "oc", "")
+
+	download, err := DownloadFrom(p, "4.15.0+corp.1", server.URL+"/builds/oc.tar.gz")
+	require.NoError(t, err)
+
+	fileName := filepath.Join(t.TempDir(), "oc-v4.15.0+corp.1")
+	require.NoError(t, Save(p, fileName, download))
+
+	actual, err := os.ReadFile(fileName) // nolint: gosec
+	require.NoError(t, err)
+	assert.Equal(t, []byte("patched oc"), actual)
+
+	// Archives without the binary are rejected
+	require.Error(t, Save(testProvider(t, "kubectl", ""), filepath.Join(t.TempDir(), "kubectl-v1.0.0"), download))
+}
+
+func TestDownloadNotFound(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := Download(testProvider(t, "kubectl", server.URL+"/release/v%s/bin/%s/%s/kubectl"), "1.0.0", "v1.0.0", false)
+
+	var downloadErr *DownloadError
+
+	require.ErrorAs(t, err, &downloadErr)
+	assert.Equal(t, "binary not found", downloadErr.Err)
+}
+
+func TestDownloadUsesCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		_, err := rw.Write([]byte("kubectl binary"))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	p := testProvider(t, "kubectl", server.URL+"/release/v%s/bin/%s/%s/kubectl")
+
+	first, err := Download(p, "1.0.0", "v1.0.0", false)
+	require.NoError(t, err)
+
+	second, err := Download(p, "1.0.0", "v1.0.0", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, requests)
+
+	// Unverified artifacts aren't trusted when verification is required
+	_, err = Download(p, "1.0.0", "v1.0.0", true)
+	require.Error(t, err)
+	assert.Equal(t, 3, requests)
+}
+
+func TestDownloadUsesReleaseAsset(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var (
+		server *httptest.Server
+		asset  = fmt.Sprintf("assetool-%s-%s", osArch.OS, osArch.Arch)
+	)
+
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/repos/a/assetool/releases/tags/v1.0.0":
+			fmt.Fprintf(rw, `{"tag_name": "v1.0.0", "assets": [
+				{"name": "%s.sha256", "browser_download_url": "%s/download/%s.sha256"},
+				{"name": "%s", "browser_download_url": "%s/download/%s", "size": 16}
+			]}`, asset, server.URL, asset, asset, server.URL, asset)
+		case "/download/" + asset:
+			_, _ = rw.Write([]byte("assetool binary"))
+		default:
+			http.NotFound(rw, req)
+		}
+	}))
+	defer server.Close()
+
+	require.NoError(t, provider.RegisterDefinition(provider.Definition{
+		Name:   "assetool",
+		GitHub: "a/assetool",
+		URL:    server.URL + "/template/{{.Version}}",
+		Asset:  "assetool",
+	}))
+
+	p, err := provider.New("assetool", config.Tool{VersionsAPI: server.URL + "/repos/a/assetool/releases?per_page=100&page="})
+	require.NoError(t, err)
+
+	download, err := Download(p, "1.0.0", "v1.0.0", false)
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(download) // nolint: gosec
+	require.NoError(t, err)
+	assert.Equal(t, []byte("assetool binary"), actual)
+}
+
+func TestDownloadMissingAsset(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	other := "windows"
+	if osArch.OS == other {
+		other = "linux"
+	}
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/repos/a/assetonly/releases/tags/v1.0.0" {
+			http.NotFound(rw, req)
+			return
+		}
+
+		fmt.Fprintf(rw, `{"tag_name": "v1.0.0", "assets": [
+			{"name": "assetonly-%s-arm64", "browser_download_url": "%s/download/assetonly-%s-arm64"}
+		]}`, other, server.URL, other)
+	}))
+	defer server.Close()
+
+	// Without a url template, there's nothing else to download
+	require.NoError(t, provider.RegisterDefinition(provider.Definition{
+		Name:   "assetonly",
+		GitHub: "a/assetonly",
+		Asset:  "assetonly",
+	}))
+
+	p, err := provider.New("assetonly", config.Tool{VersionsAPI: server.URL + "/repos/a/assetonly/releases?page="})
+	require.NoError(t, err)
+
+	_, err = Download(p, "1.0.0", "v1.0.0", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), osArch.String())
+	assert.Contains(t, err.Error(), "only for: "+other+"/arm64")
+}
+
+func TestDownloadAssetRateLimited(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var releaseRequests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/repos/") {
+			atomic.AddInt32(&releaseRequests, 1)
+			rw.Header().Set("X-Ratelimit-Remaining", "0")
+			rw.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+			rw.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		_, _ = rw.Write([]byte("template binary"))
+	}))
+	defer server.Close()
+
+	require.NoError(t, provider.RegisterDefinition(provider.Definition{
+		Name:   "limitedtool",
+		GitHub: "a/limitedtool",
+		URL:    server.URL + "/template/{{.Version}}",
+		Asset:  "limitedtool",
+	}))
+
+	p, err := provider.New("limitedtool", config.Tool{VersionsAPI: server.URL + "/repos/a/limitedtool/releases?page="})
+	require.NoError(t, err)
+
+	// The url template is used right away, without waiting for the reset
+	download, err := Download(p, "1.0.0", "1.0.0", false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&releaseRequests))
+
+	actual, err := os.ReadFile(download) // nolint: gosec
+	require.NoError(t, err)
+	assert.Equal(t, []byte("template binary"), actual)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestWriteBinaryIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "kubectl-v1.31.0")
+
+	err := writeBinary(fileName, io.MultiReader(strings.NewReader("partial"), failingReader{}))
+	require.Error(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, writeBinary(fileName, strings.NewReader("kubectl binary")))
+
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "kubectl-v1.31.0", entries[0].Name())
+}
+
+func TestCleanStaged(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+
+	files := map[string]bool{
+		".kubectl-v1.31.0.tmp-123": false,
+		".helm-v3.19.0.tmp-456":    true,
+		"kubectl-v1.31.0":          true,
+		".kubectl-version":         true,
+	}
+
+	for name, keep := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(""), 0600))
+
+		if !keep || name == "kubectl-v1.31.0" {
+			require.NoError(t, os.Chtimes(path, old, old))
+		}
+	}
+
+	require.NoError(t, CleanStaged(dir))
+
+	for name, keep := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.Equal(t, keep, err == nil, name)
+	}
+}
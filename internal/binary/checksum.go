@@ -0,0 +1,124 @@
+package binary
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/provider"
+)
+
+type ChecksumError struct {
+	Err      string
+	URL      string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	var error string
+	if e.Expected == "" {
+		error = fmt.Sprintf("%s\nurl: %s", e.Err, e.URL)
+	} else {
+		error = fmt.Sprintf("%s\nurl: %s\nexpected: %s\nactual: %s", e.Err, e.URL, e.Expected, e.Actual)
+	}
+
+	return error
+}
+
+// Verify downloads the published digest for the artifact of the given version,
+// downloaded from url, and compares it with the sha256 of the downloaded file.
+// Tools that don't publish a checksum can't be verified.
+func Verify(p provider.Provider, version string, url string, download string) error {
+	sumURL := p.ChecksumURL(version, osArch)
+
+	if sumURL == "" {
+		return &ChecksumError{Err: "no checksum published for " + p.Name(), URL: url}
+	}
+
+	logging.Debug("Downloading checksum...", "url", sumURL)
+
+	resp, err := http.Get(sumURL) // nolint
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &DownloadError{"checksum not found", sumURL, string(data)}
+	} else if resp.StatusCode != http.StatusOK {
+		return &DownloadError{"unhandled error", sumURL, string(data)}
+	}
+
+	expected, ok := parseChecksum(data, path.Base(url))
+	if !ok {
+		return &ChecksumError{Err: "checksum not found for " + path.Base(url), URL: sumURL}
+	}
+
+	actual, err := sha256File(download)
+	if err != nil {
+		return err
+	}
+
+	logging.Debug("comparing checksums", "expected", expected, "actual", actual)
+
+	if !strings.EqualFold(expected, actual) {
+		return &ChecksumError{"checksum mismatch", url, expected, actual}
+	}
+
+	return nil
+}
+
+// parseChecksum extracts the digest of fileName from the content of a checksum
+// file. It supports both bare digests (kubectl) and sha256sum style files with
+// one "<digest>  <file>" entry per line (helm, okd).
+func parseChecksum(data []byte, fileName string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			return fields[0], true
+		default:
+			if strings.TrimPrefix(fields[1], "*") == fileName {
+				return fields[0], true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func sha256File(fileName string) (string, error) {
+	f, err := os.Open(fileName) // nolint: gosec
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,103 @@
+package binary
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	if testing.Verbose() {
+		logging.Setup("debug")
+	} else {
+		logging.Setup("error")
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestParseChecksum(t *testing.T) {
+	var flagtests = []struct {
+		testName string
+		input    string
+		fileName string
+		expected string
+		found    bool
+	}{
+		{"bare digest", "abc123\n", "kubectl", "abc123", true},
+		{"sha256sum single", "abc123  helm-v3.19.0-linux-amd64.tar.gz\n", "helm-v3.19.0-linux-amd64.tar.gz", "abc123", true},
+		{
+			"sha256sum multiple",
+			"aaa  openshift-client-linux-4.15.0.tar.gz\nbbb  openshift-client-mac-4.15.0.tar.gz\n",
+			"openshift-client-mac-4.15.0.tar.gz",
+			"bbb",
+			true,
+		},
+		{"binary mode marker", "abc123 *oc.zip\n", "oc.zip", "abc123", true},
+		{"missing entry", "aaa  openshift-client-linux-4.15.0.tar.gz\n", "other.tar.gz", "", false},
+		{"empty", "", "kubectl", "", false},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			actual, found := parseChecksum([]byte(tt.input), tt.fileName)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	var (
+		body   = []byte("fake kubectl binary")
+		sum    = sha256.Sum256(body)
+		digest = hex.EncodeToString(sum[:])
+	)
+
+	var flagtests = []struct {
+		testName string
+		served   string
+		status   int
+		errType  any
+	}{
+		{"matching checksum", digest, http.StatusOK, nil},
+		{"mismatching checksum", "0000", http.StatusOK, &ChecksumError{}},
+		{"missing checksum", "", http.StatusNotFound, &DownloadError{}},
+	}
+
+	download := filepath.Join(t.TempDir(), "kubectl")
+	require.NoError(t, os.WriteFile(download, body, 0600))
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(tt.status)
+				_, err := rw.Write([]byte(tt.served))
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			p := testProvider(t, "kubectl", server.URL+"/release/v%s/bin/%s/%s/kubectl")
+
+			err := Verify(p, "1.31.0", p.DownloadURL("1.31.0", osArch), download)
+
+			if tt.errType == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.IsType(t, tt.errType, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package binary
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/cache"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+)
+
+const (
+	partialSuffix   = ".part"
+	validatorSuffix = ".validator"
+	downloadRetries = 3
+	// Splitting a download only pays off for big files
+	minParallelSize = 8 << 20
+)
+
+// Connections is the number of ranged requests used in parallel to download
+// big files. Servers that don't support ranges are always downloaded with one.
+var Connections = 1
+
+var errNoRanges = errors.New("server doesn't support ranges")
+
+// downloadPath returns a stable path for url that keeps the original file name,
+// since the extension is needed to identify the archive format.
+func downloadPath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	return filepath.Join(cache.DownloadsDir(), hex.EncodeToString(sum[:8])+"-"+path.Base(url))
+}
+
+// downloadResumable downloads url into partial, retrying interrupted transfers.
+// When the server supports ranges, retries continue where the previous attempt
+// stopped and the partial file is kept if every attempt fails.
+func downloadResumable(url string, partial string) error {
+	var err error
+
+	for attempt := 1; attempt <= downloadRetries; attempt++ {
+		var resumable bool
+
+		resumable, err = downloadOnce(url, partial)
+		if err == nil {
+			return nil
+		}
+
+		// Don't retry what the server explicitly answered
+		var downloadErr *DownloadError
+		if errors.As(err, &downloadErr) {
+			removePartial(partial)
+			return err
+		}
+
+		if !resumable {
+			removePartial(partial)
+		}
+
+		logging.Warn("download interrupted", "attempt", attempt, "resumable", resumable, "error", err)
+	}
+
+	return err
+}
+
+// downloadOnce downloads url into partial, appending to it when it already has
+// content and the server honours the range. The range is only asked for if the
+// file is still the one partial has the start of, as told by the validator kept
+// next to it, and appended only if it starts where partial ends. It reports if
+// what was written can be resumed.
+func downloadOnce(url string, partial string) (bool, error) {
+	var (
+		offset  int64
+		ifRange string
+		flags   = os.O_CREATE | os.O_WRONLY
+	)
+
+	if info, err := os.Stat(partial); err == nil && info.Size() > 0 {
+		if v, err := os.ReadFile(partial + validatorSuffix); err == nil && len(v) > 0 {
+			offset = info.Size()
+			ifRange = string(v)
+		} else {
+			logging.Debug("the partial download can't be validated, starting over", "path", partial)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", ifRange)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return offset > 0, err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if start, _, ok := contentRange(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return false, fmt.Errorf("the server resumed the download at %q instead of byte %d",
+				resp.Header.Get("Content-Range"), offset)
+		}
+
+		logging.Debug("resuming download", "offset", offset)
+
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The whole file, either because it changed or it's the first attempt
+		offset = 0
+		flags |= os.O_TRUNC
+
+		if err := saveValidator(partial, resp); err != nil {
+			return false, err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The remote file changed, start from scratch
+		return false, errors.New("range not satisfiable")
+	default:
+		// Error pages are small, so there's no harm in reading them whole
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return false, err
+		}
+
+		if resp.StatusCode == http.StatusNotFound {
+			return false, &DownloadError{"binary not found", url, string(body)}
+		}
+
+		return false, &DownloadError{"unhandled error", url, string(body)}
+	}
+
+	resumable := resp.StatusCode == http.StatusPartialContent || resp.Header.Get("Accept-Ranges") == "bytes"
+
+	file, err := os.OpenFile(partial, flags, 0600) // nolint: mnd
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	total := resp.ContentLength
+	if total >= 0 {
+		total += offset
+	}
+
+	progress := newProgress(total)
+	progress.written = offset
+
+	defer progress.Done()
+
+	_, err = io.Copy(io.MultiWriter(file, progress), resp.Body)
+
+	return resumable, err
+}
+
+// validator returns what identifies the version of the file resp serves, to
+// resume it with If-Range: its strong ETag or else its Last-Modified date.
+func validator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return resp.Header.Get("Last-Modified")
+}
+
+// saveValidator keeps the validator of resp next to partial, or removes the
+// one of a previous download when it has none, so partial isn't resumed.
+func saveValidator(partial string, resp *http.Response) error {
+	v := validator(resp)
+	if v == "" {
+		if err := os.Remove(partial + validatorSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	return os.WriteFile(partial+validatorSuffix, []byte(v), 0600) // nolint: mnd
+}
+
+// removePartial removes partial along with its validator.
+func removePartial(partial string) {
+	os.Remove(partial)
+	os.Remove(partial + validatorSuffix)
+}
+
+// contentRange returns the first and last bytes of a Content-Range header,
+// like bytes 1000-65535/65536.
+func contentRange(header string) (int64, int64, bool) {
+	unit, spec, ok := strings.Cut(header, " ")
+	if !ok || unit != "bytes" {
+		return 0, 0, false
+	}
+
+	spec, _, _ = strings.Cut(spec, "/")
+
+	first, last, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || end < start {
+		return 0, 0, false
+	}
+
+	return start, end, true
+}
+
+// downloadParallel downloads url into partial splitting it in several ranges
+// fetched at the same time. It returns errNoRanges when the server or the
+// file size don't allow it.
+func downloadParallel(url string, partial string, connections int) error {
+	resp, err := http.Head(url) // nolint
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+
+	size := resp.ContentLength
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Accept-Ranges") != "bytes" || size < minParallelSize {
+		return errNoRanges
+	}
+
+	file, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600) // nolint: mnd
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	logging.Debug("downloading in parallel", "size", size, "connections", connections)
+
+	var (
+		wg       sync.WaitGroup
+		errs     = make([]error, connections)
+		chunk    = size / int64(connections)
+		progress = newProgress(size)
+	)
+
+	for i := range connections {
+		start := int64(i) * chunk
+		end := start + chunk - 1
+
+		if i == connections-1 {
+			end = size - 1
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs[i] = downloadRange(url, io.NewOffsetWriter(file, start), start, end, progress)
+		}()
+	}
+
+	wg.Wait()
+	progress.Done()
+
+	if err := errors.Join(errs...); err != nil {
+		removePartial(partial)
+		return err
+	}
+
+	return nil
+}
+
+// downloadRange writes the bytes from start to end, both included, of url to
+// dst, resuming the range when the transfer is interrupted.
+func downloadRange(url string, dst io.Writer, start int64, end int64, progress io.Writer) error {
+	var err error
+
+	for attempt := 1; attempt <= downloadRetries; attempt++ {
+		var n int64
+
+		n, err = downloadRangeOnce(url, dst, start, end, progress)
+		start += n
+
+		if err == nil {
+			return nil
+		}
+
+		var downloadErr *DownloadError
+		if errors.As(err, &downloadErr) {
+			return err
+		}
+
+		logging.Warn("range download interrupted", "attempt", attempt, "error", err)
+	}
+
+	return err
+}
+
+func downloadRangeOnce(url string, dst io.Writer, start int64, end int64, progress io.Writer) (int64, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, &DownloadError{"unexpected status for range request: " + resp.Status, url, ""}
+	}
+
+	// Any other range would be written over the ones next to it
+	first, last, ok := contentRange(resp.Header.Get("Content-Range"))
+	if !ok || first != start || last != end {
+		return 0, &DownloadError{
+			fmt.Sprintf("the server sent the range %q instead of bytes %d-%d",
+				resp.Header.Get("Content-Range"), start, end),
+			url, "",
+		}
+	}
+
+	size := end - start + 1
+
+	n, err := io.Copy(io.MultiWriter(dst, progress), io.LimitReader(resp.Body, size))
+	if err == nil && n < size {
+		// Resumed by downloadRange, like an interrupted transfer
+		err = fmt.Errorf("the range ended after %d of %d bytes: %w", n, size, io.ErrUnexpectedEOF)
+	}
+
+	return n, err
+}
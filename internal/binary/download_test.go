@@ -0,0 +1,324 @@
+package binary
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeServer serves content supporting ranges, with the ETag "v1", recording
+// the Range header of every GET request. The first failures requests are cut
+// after half the body.
+func rangeServer(t *testing.T, content []byte, failures int) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("ETag", `"v1"`)
+
+		if req.Method == http.MethodGet {
+			mu.Lock()
+			ranges = append(ranges, req.Header.Get("Range"))
+			fail := len(ranges) <= failures
+			mu.Unlock()
+
+			if fail {
+				rw.Header().Set("Accept-Ranges", "bytes")
+				rw.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				_, _ = rw.Write(content[:len(content)/2])
+				rw.(http.Flusher).Flush()
+
+				panic(http.ErrAbortHandler)
+			}
+		}
+
+		http.ServeContent(rw, req, "file", time.Time{}, bytes.NewReader(content))
+	}))
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]string(nil), ranges...)
+	}
+}
+
+func randomContent(t *testing.T, size int) []byte {
+	t.Helper()
+
+	content := make([]byte, size)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	return content
+}
+
+func TestDownloadResumesInterruptedTransfer(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	content := randomContent(t, 64<<10)
+	server, ranges := rangeServer(t, content, 1)
+
+	defer server.Close()
+
+	p := testProvider(t, "kubectl", server.URL+"/release/v%s/bin/%s/%s/kubectl")
+
+	download, err := Download(p, "1.0.0", "v1.0.0", false)
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(download) // nolint: gosec
+	require.NoError(t, err)
+	assert.Equal(t, content, actual)
+	assert.Equal(t, []string{"", "bytes=" + strconv.Itoa(len(content)/2) + "-"}, ranges())
+}
+
+func TestDownloadResumesPartialFile(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	content := randomContent(t, 64<<10)
+	server, ranges := rangeServer(t, content, 0)
+
+	defer server.Close()
+
+	p := testProvider(t, "kubectl", server.URL+"/release/v%s/bin/%s/%s/kubectl")
+	partial := downloadPath(p.DownloadURL("1.0.0", osArch)) + partialSuffix
+
+	require.NoError(t, os.MkdirAll(cache.DownloadsDir(), 0750))
+	require.NoError(t, os.WriteFile(partial, content[:1000], 0600))
+	require.NoError(t, os.WriteFile(partial+validatorSuffix, []byte(`"v1"`), 0600))
+
+	download, err := Download(p, "1.0.0", "v1.0.0", false)
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(download) // nolint: gosec
+	require.NoError(t, err)
+	assert.Equal(t, content, actual)
+	assert.Equal(t, []string{"bytes=1000-"}, ranges())
+	assert.NoFileExists(t, partial)
+	assert.NoFileExists(t, partial+validatorSuffix)
+}
+
+func TestDownloadRestartsChangedPartialFile(t *testing.T) {
+	var flagtests = []struct {
+		testName  string
+		validator string
+		ranges    []string
+	}{
+		// The server answers the whole file, since the range is of another one
+		{"other etag", `"v0"`, []string{"bytes=1000-"}},
+		// Nothing tells which file the partial download is of
+		{"no validator", "", []string{""}},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+			content := randomContent(t, 64<<10)
+			server, ranges := rangeServer(t, content, 0)
+
+			defer server.Close()
+
+			p := testProvider(t, "kubectl", server.URL+"/release/v%s/bin/%s/%s/kubectl")
+			partial := downloadPath(p.DownloadURL("1.0.0", osArch)) + partialSuffix
+
+			require.NoError(t, os.MkdirAll(cache.DownloadsDir(), 0750))
+			require.NoError(t, os.WriteFile(partial, randomContent(t, 1000), 0600))
+
+			if tt.validator != "" {
+				require.NoError(t, os.WriteFile(partial+validatorSuffix, []byte(tt.validator), 0600))
+			}
+
+			download, err := Download(p, "1.0.0", "v1.0.0", false)
+			require.NoError(t, err)
+
+			actual, err := os.ReadFile(download) // nolint: gosec
+			require.NoError(t, err)
+			assert.Equal(t, content, actual)
+			assert.Equal(t, tt.ranges, ranges())
+		})
+	}
+}
+
+func TestDownloadRejectsMisplacedRange(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	content := randomContent(t, 64<<10)
+
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+
+	// The server answers every range with the whole file
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, req.Header.Get("Range"))
+		mu.Unlock()
+
+		rw.Header().Set("ETag", `"v1"`)
+
+		if req.Header.Get("Range") != "" {
+			rw.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(content)-1, len(content)))
+			rw.WriteHeader(http.StatusPartialContent)
+		}
+
+		_, _ = rw.Write(content)
+	}))
+
+	defer server.Close()
+
+	p := testProvider(t, "kubectl", server.URL+"/release/v%s/bin/%s/%s/kubectl")
+	partial := downloadPath(p.DownloadURL("1.0.0", osArch)) + partialSuffix
+
+	require.NoError(t, os.MkdirAll(cache.DownloadsDir(), 0750))
+	require.NoError(t, os.WriteFile(partial, content[:1000], 0600))
+	require.NoError(t, os.WriteFile(partial+validatorSuffix, []byte(`"v1"`), 0600))
+
+	download, err := Download(p, "1.0.0", "v1.0.0", false)
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(download) // nolint: gosec
+	require.NoError(t, err)
+	assert.Equal(t, content, actual)
+	assert.Equal(t, []string{"bytes=1000-", ""}, ranges)
+}
+
+func TestContentRange(t *testing.T) {
+	var flagtests = []struct {
+		header string
+		start  int64
+		end    int64
+		ok     bool
+	}{
+		{"bytes 1000-65535/65536", 1000, 65535, true},
+		{"bytes 0-9/*", 0, 9, true},
+		{"bytes */65536", 0, 0, false},
+		{"bytes 10-9/65536", 0, 0, false},
+		{"bytes 1000-/65536", 0, 0, false},
+		{"items 1000-65535/65536", 0, 0, false},
+		{"", 0, 0, false},
+	}
+
+	for _, tt := range flagtests {
+		start, end, ok := contentRange(tt.header)
+		assert.Equal(t, tt.ok, ok, tt.header)
+		assert.Equal(t, tt.start, start, tt.header)
+		assert.Equal(t, tt.end, end, tt.header)
+	}
+}
+
+func TestDownloadParallel(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	content := randomContent(t, minParallelSize+12345)
+	server, ranges := rangeServer(t, content, 0)
+
+	defer server.Close()
+
+	Connections = 4
+
+	defer func() { Connections = 1 }()
+
+	p := testProvider(t, "kubectl", server.URL+"/release/v%s/bin/%s/%s/kubectl")
+
+	download, err := Download(p, "1.0.0", "v1.0.0", false)
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(download) // nolint: gosec
+	require.NoError(t, err)
+	assert.Equal(t, content, actual)
+	assert.Len(t, ranges(), 4)
+}
+
+func TestDownloadParallelShortRanges(t *testing.T) {
+	var flagtests = []struct {
+		testName  string
+		short     int
+		misplaced bool
+		err       string
+	}{
+		{"resumed", 1, false, ""},
+		{"always short", 100, false, "ended after"},
+		{"misplaced", 0, true, "instead of bytes"},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+			content := randomContent(t, minParallelSize+12345)
+
+			var (
+				mu       sync.Mutex
+				requests int
+			)
+
+			// The ranges are answered with half their bytes, without a
+			// Content-Length, so they end cleanly, or with the first range
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				var start, end int
+
+				if _, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+					http.ServeContent(rw, req, "file", time.Time{}, bytes.NewReader(content))
+					return
+				}
+
+				mu.Lock()
+				requests++
+				short := requests <= tt.short
+				mu.Unlock()
+
+				if tt.misplaced {
+					start, end = 0, end-start
+				}
+
+				rw.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+				rw.WriteHeader(http.StatusPartialContent)
+
+				if short {
+					end = start + (end-start)/2
+				}
+
+				_, _ = rw.Write(content[start : end+1])
+			}))
+
+			defer server.Close()
+
+			Connections = 4
+
+			defer func() { Connections = 1 }()
+
+			p := testProvider(t, "kubectl", server.URL+"/release/v%s/bin/%s/%s/kubectl")
+
+			download, err := Download(p, "1.0.0", "v1.0.0", false)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			actual, err := os.ReadFile(download) // nolint: gosec
+			require.NoError(t, err)
+			assert.Equal(t, content, actual)
+		})
+	}
+}
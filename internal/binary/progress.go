@@ -0,0 +1,84 @@
+package binary
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"golang.org/x/term"
+)
+
+const (
+	progressWidth    = 30
+	progressInterval = 100 * time.Millisecond
+)
+
+// progress is an io.Writer that draws a byte progress bar on stderr. It does
+// nothing when stderr is not a terminal, so piped and CI output stays clean.
+// It's safe to use from several goroutines.
+type progress struct {
+	mu      sync.Mutex
+	out     io.Writer
+	total   int64
+	written int64
+	drawn   time.Time
+}
+
+func newProgress(total int64) *progress {
+	var out io.Writer
+
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		out = os.Stderr
+	}
+
+	return &progress{out: out, total: total}
+}
+
+func (p *progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.written += int64(len(b))
+
+	if p.out != nil && time.Since(p.drawn) >= progressInterval {
+		p.draw()
+	}
+
+	return len(b), nil
+}
+
+// Done draws the final state of the bar and moves to the next line.
+func (p *progress) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.out == nil || p.written == 0 {
+		return
+	}
+
+	p.draw()
+	fmt.Fprintln(p.out)
+}
+
+func (p *progress) draw() {
+	p.drawn = time.Now()
+
+	// Servers may not send the length, show just the downloaded bytes then
+	if p.total <= 0 {
+		fmt.Fprintf(p.out, "\r%s", helpers.HumanBytes(p.written))
+		return
+	}
+
+	filled := int(p.written * progressWidth / p.total)
+	if filled > progressWidth {
+		filled = progressWidth
+	}
+
+	fmt.Fprintf(p.out, "\r[%s%s] %s / %s %3d%%",
+		strings.Repeat("=", filled), strings.Repeat(" ", progressWidth-filled),
+		helpers.HumanBytes(p.written), helpers.HumanBytes(p.total), p.written*100/p.total) // nolint: mnd
+}
@@ -0,0 +1,79 @@
+package binary
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+)
+
+const (
+	stagedPattern = ".*.tmp-*"
+	// Staged files only live while a binary is copied in place, so anything
+	// older than this was left by an interrupted install
+	stagedMaxAge = 10 * time.Minute
+)
+
+// writeBinary stages the binary in a temporary file next to fileName and
+// renames it into place once it's fully written, so fileName either doesn't
+// exist or is complete.
+func writeBinary(fileName string, src io.Reader) (err error) {
+	dir, base := filepath.Split(fileName)
+
+	dst, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	logging.Debug("staging binary", "path", dst.Name())
+
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(dst.Name())
+		}
+	}()
+
+	if _, err = io.Copy(dst, src); err != nil {
+		return err
+	}
+
+	if err = dst.Sync(); err != nil {
+		return err
+	}
+
+	if err = dst.Chmod(0750); err != nil { // nolint: mnd
+		return err
+	}
+
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(dst.Name(), fileName)
+}
+
+// CleanStaged removes the staged files left in dir by interrupted installs.
+func CleanStaged(dir string) error {
+	matches, err := filepath.Glob(filepath.Join(dir, stagedPattern))
+	if err != nil {
+		return err
+	}
+
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || info.IsDir() || time.Since(info.ModTime()) < stagedMaxAge {
+			continue
+		}
+
+		logging.Debug("removing leftover staged file", "path", match)
+
+		if err := os.Remove(match); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,355 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+)
+
+// The cache keeps the downloaded artifacts so reinstalling a version doesn't
+// need the network. Artifacts are stored once by their sha256 under blobs, and
+// the index maps every tool, version, os and arch to one of them. The lists of
+// versions of every tool are kept too, with the responses they were read from:
+//
+//	blobs/<sha256>/<file name>
+//	index/<tool>/<version>/<os>-<arch>.json
+//	downloads/<hash of the url>-<file name>, the partial downloads
+//	versions/<tool>.json
+//	responses/<sha256 of the url>.json
+const (
+	blobsDir     = "blobs"
+	indexDir     = "index"
+	downloadsDir = "downloads"
+	versionsDir  = "versions"
+	responsesDir = "responses"
+	indexExt     = ".json"
+)
+
+type Entry struct {
+	Tool     string    `json:"tool"`
+	Version  string    `json:"version"`
+	OS       string    `json:"os"`
+	Arch     string    `json:"arch"`
+	Name     string    `json:"name"`
+	URL      string    `json:"url"`
+	Digest   string    `json:"digest"`
+	Size     int64     `json:"size"`
+	Verified bool      `json:"verified"`
+	Created  time.Time `json:"created"`
+}
+
+// Path returns where the artifact of the entry is stored.
+func (e *Entry) Path() string {
+	return filepath.Join(Dir(), blobsDir, e.Digest, e.Name)
+}
+
+func (e *Entry) indexPath() string {
+	return indexPath(e.Tool, e.Version, e.OS, e.Arch)
+}
+
+// Dir returns the cache directory, $XDG_CACHE_HOME/kbm or the user cache
+// directory of the OS when it's not set.
+func Dir() string {
+	base := os.Getenv("XDG_CACHE_HOME")
+
+	if base == "" {
+		base, _ = os.UserCacheDir()
+	}
+
+	if base == "" {
+		base = os.TempDir()
+	}
+
+	return filepath.Join(base, "kbm")
+}
+
+// DownloadsDir returns where in progress downloads are kept.
+func DownloadsDir() string {
+	return filepath.Join(Dir(), downloadsDir)
+}
+
+// VersionsPath returns where the list of versions of tool is kept.
+func VersionsPath(tool string) string {
+	return filepath.Join(Dir(), versionsDir, tool+indexExt)
+}
+
+// ResponsePath returns where the last response from url is kept.
+func ResponsePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	return filepath.Join(Dir(), responsesDir, hex.EncodeToString(sum[:])+indexExt)
+}
+
+func indexPath(tool, version, goos, arch string) string {
+	return filepath.Join(Dir(), indexDir, tool, version, goos+"-"+arch+indexExt)
+}
+
+// Lookup returns the cached artifact for the given key, if any.
+func Lookup(tool, version, goos, arch string) (*Entry, bool) {
+	var entry Entry
+
+	data, err := os.ReadFile(indexPath(tool, version, goos, arch))
+	if err != nil {
+		return nil, false
+	}
+
+	if err := json.Unmarshal(data, &entry); err != nil || !validDigest(entry.Digest) {
+		logging.Debug("ignoring corrupt cache entry", "tool", tool, "version", version, "error", err)
+		return nil, false
+	}
+
+	info, err := os.Stat(entry.Path())
+	if err != nil || info.Size() != entry.Size {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// Store moves file into the cache and records it under the key of entry. The
+// digest, size and name of the entry are filled from the file.
+func Store(entry Entry, file string) (*Entry, error) {
+	digest, size, err := sha256File(file)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Digest = digest
+	entry.Size = size
+	entry.Name = filepath.Base(file)
+	entry.Created = time.Now().UTC()
+
+	blob := entry.Path()
+
+	if err := os.MkdirAll(filepath.Dir(blob), 0750); err != nil { // nolint: mnd
+		return nil, err
+	}
+
+	if err := os.Rename(file, blob); err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(entry.indexPath()), 0750); err != nil { // nolint: mnd
+		return nil, err
+	}
+
+	logging.Debug("storing artifact in cache", "path", blob, "tool", entry.Tool, "version", entry.Version)
+
+	if err := os.WriteFile(entry.indexPath(), data, 0600); err != nil { // nolint: mnd
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// List returns every entry in the cache.
+func List() ([]Entry, error) {
+	var entries []Entry
+
+	err := filepath.WalkDir(filepath.Join(Dir(), indexDir), func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipAll
+		}
+
+		if err != nil || d.IsDir() || filepath.Ext(path) != indexExt {
+			return err
+		}
+
+		var entry Entry
+
+		data, err := os.ReadFile(path) // nolint: gosec
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(data, &entry); err != nil || !validDigest(entry.Digest) {
+			logging.Debug("ignoring corrupt cache entry", "path", path, "error", err)
+			return nil
+		}
+
+		entries = append(entries, entry)
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// validDigest tells if digest is a sha256 in hex, as Store records them, so
+// the entries edited by hand don't point out of the blobs.
+func validDigest(digest string) bool {
+	if len(digest) != hex.EncodedLen(sha256.Size) {
+		return false
+	}
+
+	_, err := hex.DecodeString(digest)
+
+	return err == nil
+}
+
+// Remove deletes the entry from the index. Its artifact is deleted by Prune
+// once no other entry uses it.
+func Remove(entry Entry) error {
+	err := os.Remove(entry.indexPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// Remove the version directory if it's empty, ignoring the error if it's not
+	_ = os.Remove(filepath.Dir(entry.indexPath()))
+
+	return nil
+}
+
+// staleDownload is how long a partial download is kept to be resumed, after
+// it's last written to.
+const staleDownload = 7 * 24 * time.Hour
+
+// Prune deletes the artifacts no entry uses and the partial downloads that
+// haven't been resumed for long.
+func Prune() error {
+	used := map[string]bool{}
+
+	entries, err := List()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		used[entry.Digest] = true
+	}
+
+	blobs, err := os.ReadDir(filepath.Join(Dir(), blobsDir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for _, blob := range blobs {
+		if used[blob.Name()] {
+			continue
+		}
+
+		logging.Debug("removing unused artifact", "digest", blob.Name())
+
+		if err := os.RemoveAll(filepath.Join(Dir(), blobsDir, blob.Name())); err != nil {
+			return err
+		}
+	}
+
+	downloads, err := os.ReadDir(DownloadsDir())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// The files of a download, like its validator, share the hash of the url,
+	// and are stale once none of them has been written to for long
+	written := map[string]time.Time{}
+
+	for _, download := range downloads {
+		info, err := download.Info()
+		if err != nil {
+			return err
+		}
+
+		key, _, _ := strings.Cut(download.Name(), "-")
+		if info.ModTime().After(written[key]) {
+			written[key] = info.ModTime()
+		}
+	}
+
+	for _, download := range downloads {
+		if key, _, _ := strings.Cut(download.Name(), "-"); time.Since(written[key]) < staleDownload {
+			continue
+		}
+
+		logging.Debug("removing stale partial download", "file", download.Name())
+
+		if err := os.RemoveAll(filepath.Join(DownloadsDir(), download.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveVersions deletes the lists of versions of tools, which are fetched
+// again when needed.
+func RemoveVersions(tools ...string) error {
+	for _, tool := range tools {
+		err := os.Remove(VersionsPath(tool))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveLists deletes the lists of versions of every tool, with the responses
+// they were read from.
+func RemoveLists() error {
+	for _, dir := range []string{filepath.Join(Dir(), versionsDir), filepath.Join(Dir(), responsesDir)} {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Size returns the disk space used by the cache.
+func Size() (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(Dir(), func(_ string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipAll
+		}
+
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+
+		return nil
+	})
+
+	return size, err
+}
+
+func sha256File(fileName string) (string, int64, error) {
+	f, err := os.Open(fileName) // nolint: gosec
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	if testing.Verbose() {
+		logging.Setup("debug")
+	} else {
+		logging.Setup("error")
+	}
+
+	os.Exit(m.Run())
+}
+
+func storeFile(t *testing.T, entry Entry, name string, content string) *Entry {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+
+	stored, err := Store(entry, file)
+	require.NoError(t, err)
+
+	return stored
+}
+
+func TestStoreAndLookup(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	_, ok := Lookup("kubectl", "1.31.0", "linux", "amd64")
+	assert.False(t, ok)
+
+	stored := storeFile(t, Entry{Tool: "kubectl", Version: "1.31.0", OS: "linux", Arch: "amd64"}, "kubectl", "binary")
+
+	entry, ok := Lookup("kubectl", "1.31.0", "linux", "amd64")
+	require.True(t, ok)
+	assert.Equal(t, stored.Digest, entry.Digest)
+	assert.Equal(t, int64(len("binary")), entry.Size)
+	assert.FileExists(t, entry.Path())
+
+	_, ok = Lookup("kubectl", "1.31.0", "darwin", "arm64")
+	assert.False(t, ok)
+}
+
+func TestListSkipsCorruptEntries(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	stored := storeFile(t, Entry{Tool: "kubectl", Version: "1.31.0", OS: "linux", Arch: "amd64"}, "kubectl", "binary")
+
+	for version, data := range map[string]string{
+		"1.29.0": `{"tool": "kubectl", "version": "1.29.0", "digest": "abc"}`,
+		"1.30.0": `{"tool": "kubectl", "version": "1.30.0", "digest": "../../../etc"}`,
+		"1.32.0": `not json`,
+	} {
+		path := indexPath("kubectl", version, "linux", "amd64")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+		_, ok := Lookup("kubectl", version, "linux", "amd64")
+		assert.False(t, ok, version)
+	}
+
+	entries, err := List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, stored.Digest, entries[0].Digest)
+}
+
+func TestRemoveAndPrune(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// Both versions share the same content, so the artifact is stored once
+	first := storeFile(t, Entry{Tool: "kubectl", Version: "1.30.0", OS: "linux", Arch: "amd64"}, "kubectl", "same")
+	second := storeFile(t, Entry{Tool: "kubectl", Version: "1.31.0", OS: "linux", Arch: "amd64"}, "kubectl", "same")
+	assert.Equal(t, first.Path(), second.Path())
+
+	size, err := Size()
+	require.NoError(t, err)
+	assert.Positive(t, size)
+
+	require.NoError(t, Remove(*first))
+	require.NoError(t, Prune())
+	assert.FileExists(t, second.Path())
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(VersionsPath("kubectl")), 0750))
+	require.NoError(t, os.WriteFile(VersionsPath("kubectl"), []byte("{}"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Dir(ResponsePath("https://example.com")), 0750))
+	require.NoError(t, os.WriteFile(ResponsePath("https://example.com"), []byte("{}"), 0600))
+
+	// Partial downloads are kept to be resumed until they're stale
+	fresh := filepath.Join(DownloadsDir(), "0a1b-kubectl")
+	validator := filepath.Join(DownloadsDir(), "0a1b-kubectl.validator")
+	stale := filepath.Join(DownloadsDir(), "2c3d-kubectl")
+	old := time.Now().Add(-staleDownload - time.Hour)
+
+	require.NoError(t, os.MkdirAll(DownloadsDir(), 0750))
+
+	for _, file := range []string{fresh, validator, stale} {
+		require.NoError(t, os.WriteFile(file, []byte("partial"), 0600))
+	}
+
+	// The validator is kept as long as the download it belongs to
+	require.NoError(t, os.Chtimes(validator, old, old))
+	require.NoError(t, os.Chtimes(stale, old, old))
+
+	require.NoError(t, Remove(*second))
+	require.NoError(t, Prune())
+	assert.NoFileExists(t, second.Path())
+	assert.FileExists(t, fresh)
+	assert.FileExists(t, validator)
+	assert.NoFileExists(t, stale)
+	// The lists of versions aren't artifacts
+	assert.FileExists(t, VersionsPath("kubectl"))
+	assert.FileExists(t, ResponsePath("https://example.com"))
+
+	require.NoError(t, os.RemoveAll(DownloadsDir()))
+	require.NoError(t, RemoveLists())
+	assert.NoFileExists(t, VersionsPath("kubectl"))
+	assert.NoFileExists(t, ResponsePath("https://example.com"))
+
+	size, err = Size()
+	require.NoError(t, err)
+	assert.Zero(t, size)
+
+	entries, err := List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRemoveVersions(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(VersionsPath("kubectl")), 0750))
+
+	for _, tool := range []string{"kubectl", "kubectl-releases", "helm"} {
+		require.NoError(t, os.WriteFile(VersionsPath(tool), []byte("{}"), 0600))
+	}
+
+	require.NoError(t, RemoveVersions("kubectl", "kubectl-releases", "oc"))
+	assert.NoFileExists(t, VersionsPath("kubectl"))
+	assert.NoFileExists(t, VersionsPath("kubectl-releases"))
+	assert.FileExists(t, VersionsPath("helm"))
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/cache"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/spf13/cobra"
+)
+
+var allTools bool
+
+// datesSuffix names the cached releases of a tool the release dates are read
+// from, next to its versions.
+const datesSuffix = "-releases"
+
+// cacheEntries returns the entries of the cache for the managed binary, or for
+// every tool when --all is set.
+func cacheEntries() []cache.Entry {
+	entries, err := cache.List()
+	helpers.CheckGenericError(err)
+
+	if allTools {
+		return entries
+	}
+
+	filtered := entries[:0]
+
+	for _, entry := range entries {
+		if entry.Tool == BinaryToInstall {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered
+}
+
+func cacheList(cmd *cobra.Command, args []string) {
+	entries := cacheEntries()
+
+	if len(entries) == 0 {
+		fmt.Println("The cache is empty.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) // nolint: mnd
+	fmt.Fprintln(w, "TOOL\tVERSION\tPLATFORM\tSIZE\tVERIFIED\tDIGEST")
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\t%t\t%s\n",
+			e.Tool, e.Version, e.OS, e.Arch, helpers.HumanBytes(e.Size), e.Verified, e.Digest[:12])
+	}
+
+	_ = w.Flush()
+}
+
+func cacheClean(cmd *cobra.Command, args []string) {
+	var removed int
+
+	for _, entry := range cacheEntries() {
+		// Clean only the given versions, if any
+		if len(args) != 0 && !contains(args, entry.Version) {
+			continue
+		}
+
+		helpers.CheckGenericError(cache.Remove(entry))
+
+		removed++
+	}
+
+	helpers.CheckGenericError(cache.Prune())
+
+	// The lists of versions are only removed along with every cached binary
+	if len(args) == 0 {
+		if allTools {
+			helpers.CheckGenericError(cache.RemoveLists())
+		} else {
+			helpers.CheckGenericError(cache.RemoveVersions(BinaryToInstall, BinaryToInstall+datesSuffix))
+		}
+	}
+
+	fmt.Printf("Done! %d cached artifacts removed.\n", removed)
+}
+
+func cacheSize(cmd *cobra.Command, args []string) {
+	size, err := cache.Size()
+	helpers.CheckGenericError(err)
+
+	fmt.Printf("%s\t%s\n", helpers.HumanBytes(size), cache.Dir())
+}
+
+func contains(arr []string, str string) bool {
+	for _, a := range arr {
+		if a == str {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of downloaded binaries",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			_ = cmd.Help()
+		}
+	},
+}
+
+func init() {
+	cacheCmd.PersistentFlags().BoolVar(&allTools, "all", false, "apply to the cached binaries of every tool")
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List cached binaries",
+		Args:  cobra.NoArgs,
+		Run:   cacheList,
+	})
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:   "clean [version...]",
+		Short: "Remove cached binaries, all of them unless versions are given",
+		Run:   cacheClean,
+	})
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:   "size",
+		Short: "Show the disk space used by the cache",
+		Args:  cobra.NoArgs,
+		Run:   cacheSize,
+	})
+	RootCmd.AddCommand(cacheCmd)
+}
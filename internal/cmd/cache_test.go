@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheClean(t *testing.T) {
+	var flagtests = []struct {
+		testName string
+		args     []string
+		all      bool
+		removed  []string
+		kept     []string
+	}{
+		{"version", []string{"1.30.2"}, false, nil, []string{"kubectl", "kubectl-releases", "helm"}},
+		{"tool", nil, false, []string{"kubectl", "kubectl-releases"}, []string{"helm"}},
+		{"version of every tool", []string{"1.30.2"}, true, nil, []string{"kubectl", "kubectl-releases", "helm"}},
+		{"every tool", nil, true, []string{"kubectl", "kubectl-releases", "helm"}, nil},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			testHome(t)
+
+			previous := allTools
+			allTools = tt.all
+
+			defer func() { allTools = previous }()
+
+			for _, v := range []string{"1.30.2", "1.29.6"} {
+				file := filepath.Join(t.TempDir(), "kubectl")
+				require.NoError(t, os.WriteFile(file, []byte(v), 0600))
+
+				_, err := cache.Store(cache.Entry{Tool: "kubectl", Version: v, OS: "linux", Arch: "amd64"}, file)
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, os.MkdirAll(filepath.Dir(cache.VersionsPath("kubectl")), 0750))
+
+			for _, tool := range []string{"kubectl", "kubectl-releases", "helm"} {
+				require.NoError(t, os.WriteFile(cache.VersionsPath(tool), []byte("{}"), 0600))
+			}
+
+			captureStdout(t, func() { cacheClean(cacheCmd, tt.args) })
+
+			_, cached := cache.Lookup("kubectl", "1.30.2", "linux", "amd64")
+			assert.False(t, cached)
+
+			_, cached = cache.Lookup("kubectl", "1.29.6", "linux", "amd64")
+			assert.Equal(t, len(tt.args) != 0, cached)
+
+			for _, tool := range tt.removed {
+				assert.NoFileExists(t, cache.VersionsPath(tool))
+			}
+
+			for _, tool := range tt.kept {
+				assert.FileExists(t, cache.VersionsPath(tool))
+			}
+		})
+	}
+}
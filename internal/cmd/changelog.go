@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
+	"github.com/spf13/cobra"
+)
+
+const outputMarkdown = "markdown"
+
+var (
+	changelogOutput  string
+	changelogChannel string
+)
+
+// changelogList is what changelog prints as JSON. Scripts depend on it, so
+// fields may be added but not renamed nor removed.
+type changelogList struct {
+	Tool     string             `json:"tool"`
+	Releases []changelogRelease `json:"releases"`
+}
+
+type changelogRelease struct {
+	Version     string     `json:"version"`
+	Prerelease  bool       `json:"prerelease"`
+	ReleaseDate *time.Time `json:"release_date,omitempty"`
+	Notes       string     `json:"notes"`
+}
+
+func changelog(cmd *cobra.Command, args []string) {
+	logging.Debug("changelog called", "args", args)
+
+	if changelogOutput != outputMarkdown && changelogOutput != outputJSON {
+		fmt.Printf("Invalid output '%s', must be markdown or json.\n", changelogOutput)
+		os.Exit(1)
+	}
+
+	helpers.CheckGenericError(versions.ValidStability(changelogChannel))
+
+	// The notes come from the release pages, the unchanged ones are taken from the cache
+	releases, err := versions.GetReleases(releasesSource)
+	helpers.CheckGenericError(err)
+
+	published, err := versions.FilterReleases(releases, versions.StabilityAll)
+	helpers.CheckGenericError(err)
+
+	selected, err := changelogReleases(published, args[0], changelogChannel)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	printChangelog(versions.SortReleases(selected, 0))
+}
+
+// changelogReleases returns the releases whose notes expr asks for: the one of
+// a version, or the ones in a range like 1.28.4..1.30.1 up to the stability of
+// channel. Both can be constraints, like 1.28..1.30.
+func changelogReleases(releases []*versions.Release, expr string, channel string) ([]*versions.Release, error) {
+	from, to, isRange := strings.Cut(expr, "..")
+	if !isRange {
+		to = from
+	}
+
+	last, err := resolveRelease(releases, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isRange {
+		return []*versions.Release{last}, nil
+	}
+
+	first, err := resolveRelease(releases, from)
+	if err != nil {
+		return nil, err
+	}
+
+	if versions.Newer(first.Version, last.Version) {
+		return nil, fmt.Errorf("the range %s is reversed, it must go from the older version to the newer one", expr)
+	}
+
+	inChannel, err := versions.FilterReleases(releases, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := versions.ReleasesBetween(inChannel, first.Version, last.Version)
+
+	// The last one is always shown, even if it's not in the channel
+	if !versions.ContainsRelease(selected, last) {
+		selected = append(selected, last)
+	}
+
+	return selected, nil
+}
+
+// resolveRelease returns the release expr resolves to, a version or a
+// constraint like 1.30.
+func resolveRelease(releases []*versions.Release, expr string) (*versions.Release, error) {
+	v, err := versions.Resolve(strings.TrimSpace(expr), versions.ReleaseVersions(releases))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, release := range releases {
+		if release.Version == v {
+			return release, nil
+		}
+	}
+
+	return nil, fmt.Errorf("version %s not found", expr)
+}
+
+func printChangelog(releases []*versions.Release) {
+	if changelogOutput == outputJSON {
+		list := changelogList{Tool: BinaryToInstall, Releases: make([]changelogRelease, 0, len(releases))}
+
+		for _, release := range releases {
+			entry := changelogRelease{
+				Version:    release.Version.String(),
+				Prerelease: release.Stability() != versions.StabilityStable,
+				Notes:      release.Notes,
+			}
+
+			if !release.Published.IsZero() {
+				published := release.Published.UTC()
+				entry.ReleaseDate = &published
+			}
+
+			list.Releases = append(list.Releases, entry)
+		}
+
+		data, err := json.MarshalIndent(list, "", "  ")
+		helpers.CheckGenericError(err)
+		fmt.Println(string(data))
+
+		return
+	}
+
+	for i, release := range releases {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		title := "## " + release.Version.String()
+		if !release.Published.IsZero() {
+			title += " - " + release.Published.UTC().Format(time.DateOnly)
+		}
+
+		notes := strings.TrimSpace(strings.ReplaceAll(release.Notes, "\r\n", "\n"))
+		if notes == "" {
+			notes = "_No release notes published._"
+		}
+
+		fmt.Printf("%s\n\n%s\n", title, notes)
+	}
+}
+
+func init() {
+	var changelogCmd = &cobra.Command{
+		Use:   "changelog <version|from..to>",
+		Short: "Show the release notes of a version, or of every version in a range",
+		Long: `Show the release notes of a version, or of every version in a range. A range
+like 1.28.4..1.30.1 has the versions after 1.28.4 up to 1.30.1, the ones you get
+upgrading from 1.28.4 to 1.30.1.`,
+		Args: cobra.ExactArgs(1),
+		Run:  changelog,
+	}
+
+	changelogCmd.Flags().StringVarP(&changelogOutput,
+		"output", "o", outputMarkdown, "output format: markdown or json")
+	changelogCmd.Flags().StringVar(&changelogChannel, "channel", versions.StabilityStable,
+		"show the releases in the range up to this stability: stable, rc, beta, alpha or all")
+	RootCmd.AddCommand(changelogCmd)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelogReleases(t *testing.T) {
+	var flagtests = []struct {
+		testName string
+		expr     string
+		channel  string
+		expected []string
+		err      string
+	}{
+		{"version", "1.30.0", versions.StabilityStable, []string{"1.30.0"}, ""},
+		{"constraint", "1.29", versions.StabilityStable, []string{"1.29.1"}, ""},
+		{"prerelease outside the channel", "1.30.0-rc.1", versions.StabilityStable, []string{"1.30.0-rc.1"}, ""},
+		{
+			"range", "1.28.4..1.30.1", versions.StabilityStable,
+			[]string{"1.30.1", "1.30.0", "1.29.1", "1.29.0"}, "",
+		},
+		{
+			"range with prereleases", "1.28.4..1.30.1", versions.StabilityRC,
+			[]string{"1.30.1", "1.30.0", "1.30.0-rc.1", "1.29.1", "1.29.0"}, "",
+		},
+		{"constraints as ends", "1.28..1.29", versions.StabilityStable, []string{"1.29.1", "1.29.0"}, ""},
+		{"constraint as the end", "1.29.0..1.30", versions.StabilityStable, []string{"1.30.1", "1.30.0", "1.29.1"}, ""},
+		{
+			"end outside the channel", "1.30.0..1.31.0-beta.0", versions.StabilityStable,
+			[]string{"1.31.0-beta.0", "1.30.1"}, "",
+		},
+		{"only the end outside the channel", "1.29.1..1.30.0-rc.1", versions.StabilityStable, []string{"1.30.0-rc.1"}, ""},
+		{"same ends", "1.30.0..1.30.0", versions.StabilityStable, []string{"1.30.0"}, ""},
+		{"reversed", "1.30.1..1.28.4", versions.StabilityStable, nil, "reversed"},
+		{"reversed constraints", "1.30..1.29", versions.StabilityStable, nil, "reversed"},
+		{"unknown version", "1.27.0", versions.StabilityStable, nil, "not found"},
+		{"unknown start", "1.27.0..1.30.1", versions.StabilityStable, nil, "not found"},
+		// Constraints don't match prereleases unless they name one
+		{"constraint without stable releases", "1.30.0..1.31", versions.StabilityStable, nil, "1.31"},
+		{"invalid channel", "1.28.4..1.30.1", "nightly", nil, "nightly"},
+	}
+
+	releases := testReleases(t, "1.28.4", "1.29.0", "1.29.1", "1.30.0-rc.1", "1.30.0", "1.30.1", "1.31.0-beta.0")
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			selected, err := changelogReleases(releases, tt.expr, tt.channel)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			actual := make([]string, 0, len(selected))
+			for _, release := range versions.SortReleases(selected, 0) {
+				actual = append(actual, release.Version.String())
+			}
+
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/hashicorp/go-version"
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/binary"
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers/fzf"
@@ -14,6 +15,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	insecureSkipVerify bool
+	fromFile           string
+	fromURL            string
+)
+
 func install(cmd *cobra.Command, args []string) { // nolint:funlen
 	var (
 		err    error
@@ -21,17 +28,30 @@ func install(cmd *cobra.Command, args []string) { // nolint:funlen
 	)
 
 	var version string
-	var body []byte
 
 	logging.Debug("install called", "args", args)
 
-	if len(args) == 0 {
+	if fromFile != "" || fromURL != "" {
+		// The version of a custom binary can't be guessed, it's a label given by the user
+		if len(args) == 0 {
+			fmt.Println("You must specify a version label for the binary, like 1.30.2+corp.1")
+			os.Exit(0)
+		}
+
+		version, err = versions.NormalizeLabel(args[0])
+		if err != nil {
+			fmt.Printf("The version label '%s' is not valid: %s\n", args[0], err)
+			os.Exit(1)
+		}
+	} else if len(args) == 0 {
 		// No version provided; use embedded fuzzy finder to select from remote versions
-		versionList, err := versions.GetRemoteVersions(VersionsAPI)
+		releases, err := versions.GetReleases(versionSource)
 		helpers.CheckGenericError(err)
-		versionList, err = versions.SortVersions(versionList, false, false)
+		releases, err = versions.FilterReleases(releases, versions.StabilityStable)
 		helpers.CheckGenericError(err)
 
+		versionList := versions.ReleaseVersions(versions.SortReleases(releases, versions.DefaultLimit))
+
 		items := make([]string, 0, len(versionList))
 		for _, v := range versionList {
 			items = append(items, v.String())
@@ -49,8 +69,31 @@ func install(cmd *cobra.Command, args []string) { // nolint:funlen
 		}
 
 		version = sel
-	} else {
+	} else if versions.IsExact(args[0]) {
 		version = args[0]
+	} else if versions.IsChannel(args[0]) {
+		// A channel like stable or latest-1.30, read from upstream when it publishes them
+		resolved, err := versions.ResolveChannel(versionSource, args[0])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		version = resolved.String()
+		fmt.Printf("Resolved %s to %s.\n", args[0], version)
+	} else {
+		// A constraint like 1.29 or ~> 1.29.0, resolved to the newest published match
+		versionList, err := versionSource.Versions()
+		helpers.CheckGenericError(err)
+
+		resolved, err := versions.Resolve(args[0], versionList)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		version = resolved.String()
+		fmt.Printf("Resolved %s to %s.\n", args[0], version)
 	}
 	// Check if os/arch is supported
 	osArch, err = helpers.GetOSArch()
@@ -75,15 +118,44 @@ func install(cmd *cobra.Command, args []string) { // nolint:funlen
 		fileName += windowsSuffix
 	}
 
+	// Remove what previous interrupted installs may have left
+	if err := binary.CleanStaged(filepath.Dir(fileName)); err != nil {
+		logging.Warn("could not clean leftover files", "error", err)
+	}
+
 	// Check if binary exists locally
 	if helpers.FileExists(fileName) {
 		logging.Infof("The version %s is already installed!\n", version)
 		os.Exit(0)
 	}
 
-	// Download binary
-	logging.Info("downloading binary", "version", version)
-	body, err := binary.Download(version, BinaryDownloadURL)
+	var download string
+
+	switch {
+	case fromFile != "":
+		logging.Info("installing from file", "path", fromFile, "version", version)
+
+		download = fromFile
+		if !helpers.FileExists(download) {
+			fmt.Printf("The file %s doesn't exist.\n", download)
+			os.Exit(1)
+		}
+	case fromURL != "":
+		logging.Warn("binaries installed from an url can't be verified against a checksum", "url", fromURL)
+		logging.Info("downloading binary", "url", fromURL, "version", version)
+
+		download, err = binary.DownloadFrom(binaryProvider, version, fromURL)
+	default:
+		if insecureSkipVerify {
+			logging.Warn("skipping checksum verification", "version", version)
+		}
+
+		// Download binary, or take it from the cache
+		logging.Info("downloading binary", "version", version)
+
+		download, err = binary.Download(binaryProvider, version, releaseTag(version), !insecureSkipVerify)
+	}
+
 	// Check for errors when downloading the binary
 	if err, ok := err.(*binary.DownloadError); ok {
 		if err.Err == "binary not found" {
@@ -99,9 +171,16 @@ func install(cmd *cobra.Command, args []string) { // nolint:funlen
 		}
 	}
 
+	// Check the binary matched the upstream published digest
+	if err, ok := err.(*binary.ChecksumError); ok {
+		fmt.Println("The checksum of the downloaded binary could not be verified, refusing to install it:")
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	helpers.CheckGenericError(err)
 
-	err = binary.Save(fileName, body)
+	err = binary.Save(binaryProvider, fileName, download)
 
 	helpers.CheckGenericError(err)
 
@@ -109,13 +188,52 @@ func install(cmd *cobra.Command, args []string) { // nolint:funlen
 	fmt.Printf("Done! Saving it at %s.\n", fileName)
 }
 
+// releaseTag returns the tag of the release of v in the listing, so its assets
+// are looked up by it. It's empty when the provider has no assets or v isn't
+// listed, the url template being used then.
+func releaseTag(v string) string {
+	if binaryProvider.AssetName() == "" {
+		return ""
+	}
+
+	want, err := version.NewVersion(v)
+	if err != nil {
+		return ""
+	}
+
+	releases, err := versions.GetReleases(versionSource)
+	if err != nil {
+		logging.Debug("could not list the releases", "error", err)
+		return ""
+	}
+
+	for _, release := range releases {
+		if sameVersion(release.Version, want) {
+			return release.Tag
+		}
+	}
+
+	logging.Debug("the version isn't listed", "version", v)
+
+	return ""
+}
+
 func init() {
 	var installCmd = &cobra.Command{
-		Use:   "install",
+		Use:   "install [version|constraint|channel]",
 		Short: "Install binary",
 		Args:  cobra.MaximumNArgs(1),
 		Run:   install,
 	}
 
+	installCmd.Flags().BoolVar(&insecureSkipVerify,
+		"insecure-skip-verify", false, "don't verify the checksum of the downloaded binary")
+	installCmd.Flags().IntVar(&binary.Connections,
+		"connections", 1, "number of parallel connections used to download big files")
+	installCmd.Flags().StringVar(&fromFile,
+		"from-file", "", "install a local binary or archive under the given version label")
+	installCmd.Flags().StringVar(&fromURL,
+		"from-url", "", "install a binary or archive from an url under the given version label")
+	installCmd.MarkFlagsMutuallyExclusive("from-file", "from-url")
 	RootCmd.AddCommand(installCmd)
 }
@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var listCmd = &cobra.Command{
@@ -14,9 +19,114 @@ var listCmd = &cobra.Command{
 	},
 }
 
+var (
+	// minor is the series listed, like 1.28, or stream as OKD calls it
+	minor          string
+	latestPerMinor bool
+)
+
+// listFilter returns the stability channel and how many versions to list, as
+// asked with the list flags. --all-releases is the all channel, and
+// --all-versions lists them all whatever the limit.
+func listFilter(cmd *cobra.Command) (string, int) {
+	channel, err := cmd.Flags().GetString("channel")
+	helpers.CheckGenericError(err)
+	allReleases, err := cmd.Flags().GetBool("all-releases")
+	helpers.CheckGenericError(err)
+	allVersions, err := cmd.Flags().GetBool("all-versions")
+	helpers.CheckGenericError(err)
+	limit, err := cmd.Flags().GetInt("limit")
+	helpers.CheckGenericError(err)
+
+	if allReleases {
+		channel = versions.StabilityAll
+	}
+
+	helpers.CheckGenericError(versions.ValidStability(channel))
+	helpers.CheckGenericError(validateOutput())
+	helpers.CheckGenericError(checkOKDFlags(cmd))
+
+	if limit < 0 {
+		helpers.CheckGenericError(fmt.Errorf("invalid limit %d, must be 0 for no limit or more", limit))
+	}
+
+	if allVersions {
+		limit = 0
+	}
+
+	return channel, limit
+}
+
+// okdFlags are the flags that only apply to the OKD builds of oc.
+var okdFlags = []string{"stream", "flavor"}
+
+// checkOKDFlags fails when any of the okdFlags is given for a tool whose
+// versions aren't OKD builds.
+func checkOKDFlags(cmd *cobra.Command) error {
+	if BinaryToInstall == "oc" {
+		return nil
+	}
+
+	for _, name := range okdFlags {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+			return fmt.Errorf("--%s only applies to the OKD builds of oc, not to %s", name, BinaryToInstall)
+		}
+	}
+
+	return nil
+}
+
+// hideOKDFlags hides the okdFlags from the help of the tools other than oc.
+func hideOKDFlags() {
+	if BinaryToInstall == "oc" {
+		return
+	}
+
+	for _, flags := range []*pflag.FlagSet{listCmd.PersistentFlags(), remoteCmd.Flags()} {
+		for _, name := range okdFlags {
+			if flags.Lookup(name) != nil {
+				_ = flags.MarkHidden(name)
+			}
+		}
+	}
+}
+
+// filterMinor keeps the releases of the minor asked with --minor, if any.
+func filterMinor(releases []*versions.Release) []*versions.Release {
+	if minor == "" {
+		return releases
+	}
+
+	releases, err := versions.FilterStream(releases, minor)
+	helpers.CheckGenericError(err)
+
+	return releases
+}
+
+// sortList returns the releases to list, from the newest to the oldest and up
+// to limit of them, only the newest of every minor with --latest-per-minor.
+func sortList(releases []*versions.Release, limit int) []*versions.Release {
+	if latestPerMinor {
+		releases = versions.LatestPerMinor(releases)
+	}
+
+	return versions.SortReleases(releases, limit)
+}
+
 // listCmd represents the list command
 func init() {
 	listCmd.PersistentFlags().Bool("all-releases", false, "return all releases, including alpha, beta and rc releases")
 	listCmd.PersistentFlags().Bool("all-versions", false, "return all versions")
+	listCmd.PersistentFlags().Int("limit", versions.DefaultLimit, "return at most this many versions, 0 for all of them")
+	listCmd.PersistentFlags().StringVar(&minor, "minor", "", "only return the versions of a minor, like 1.28")
+	listCmd.PersistentFlags().StringVar(&minor,
+		"stream", "", "only return the versions of a stream, like 4.15, the same as --minor")
+	listCmd.PersistentFlags().BoolVar(&latestPerMinor,
+		"latest-per-minor", false, "only return the newest version of every minor")
+	listCmd.MarkFlagsMutuallyExclusive("minor", "stream")
+	listCmd.PersistentFlags().StringVarP(&listOutput,
+		"output", "o", "", "output format: json, yaml or table, a version per line if not set")
+	listCmd.PersistentFlags().String("channel",
+		"stable", "return the releases up to this stability: stable, rc, beta, alpha or all")
 	RootCmd.AddCommand(listCmd)
 }
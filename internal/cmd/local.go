@@ -12,10 +12,9 @@ import (
 
 func local(cmd *cobra.Command, args []string) {
 	var (
-		err         error
-		allReleases bool
-		allVersions bool
-		versions    []*version.Version
+		err      error
+		releases []*vers.Release
+		versions []*version.Version
 	)
 
 	if len(args) != 0 {
@@ -26,23 +25,17 @@ func local(cmd *cobra.Command, args []string) {
 		os.Exit(0)
 	}
 
-	allReleases, err = cmd.Flags().GetBool("all-releases")
-
-	helpers.CheckGenericError(err)
-
-	allVersions, err = cmd.Flags().GetBool("all-versions")
-
-	helpers.CheckGenericError(err)
+	channel, limit := listFilter(cmd)
 
 	versions, err = vers.GetLocalVersions(BinaryToInstall)
 
 	helpers.CheckGenericError(err)
 
-	versions, err = vers.SortVersions(versions, allReleases, allVersions)
+	releases, err = vers.FilterReleases(withCachedDetails(vers.NewReleases(versions)), channel)
 
 	helpers.CheckGenericError(err)
 
-	vers.PrintVersions(versions)
+	printReleases(sortList(filterMinor(releases), limit))
 }
 
 // localCmd represents the local command
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v3"
+)
+
+// The formats of the list commands, set with --output. The default one is a
+// version per line.
+const (
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+var listOutput string
+
+// versionList is what the list commands print as JSON or YAML. Scripts depend
+// on it, so fields may be added but not renamed nor removed.
+type versionList struct {
+	Tool     string        `json:"tool" yaml:"tool"`
+	Versions []versionInfo `json:"versions" yaml:"versions"`
+}
+
+type versionInfo struct {
+	Version string `json:"version" yaml:"version"`
+	// Installed tells if it's in ~/.bin, at Path
+	Installed bool `json:"installed" yaml:"installed"`
+	// Active tells if it's the one selected with use
+	Active bool `json:"active" yaml:"active"`
+	// Pinned tells if the version file of the current directory selects it
+	Pinned      bool       `json:"pinned" yaml:"pinned"`
+	Prerelease  bool       `json:"prerelease" yaml:"prerelease"`
+	ReleaseDate *time.Time `json:"release_date,omitempty" yaml:"release_date,omitempty"`
+	Path        string     `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+func validateOutput() error {
+	switch listOutput {
+	case "", outputJSON, outputYAML, outputTable:
+		return nil
+	default:
+		return fmt.Errorf("invalid output %q, must be json, yaml or table", listOutput)
+	}
+}
+
+// printReleases prints releases in the format asked with --output.
+func printReleases(releases []*versions.Release) {
+	if listOutput == "" {
+		versions.PrintVersions(versions.ReleaseVersions(releases))
+		return
+	}
+
+	list := versionList{Tool: BinaryToInstall, Versions: describe(releases)}
+
+	switch listOutput {
+	case outputJSON:
+		data, err := json.MarshalIndent(list, "", "  ")
+		helpers.CheckGenericError(err)
+		fmt.Println(string(data))
+	case outputYAML:
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2) // nolint: mnd
+		helpers.CheckGenericError(encoder.Encode(list))
+		helpers.CheckGenericError(encoder.Close())
+	case outputTable:
+		printTable(list.Versions)
+	}
+}
+
+func printTable(infos []versionInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) // nolint: mnd
+	fmt.Fprintln(w, "VERSION\tINSTALLED\tACTIVE\tPINNED\tPRERELEASE\tRELEASED\tPATH")
+
+	for _, info := range infos {
+		released := "-"
+		if info.ReleaseDate != nil {
+			released = info.ReleaseDate.Format(time.DateOnly)
+		}
+
+		fmt.Fprintf(w, "%s\t%t\t%t\t%t\t%t\t%s\t%s\n",
+			info.Version, info.Installed, info.Active, info.Pinned, info.Prerelease, released, info.Path)
+	}
+
+	_ = w.Flush()
+}
+
+// describe returns what's known of every release: whether it's installed,
+// selected globally or in the current directory, and when it was released.
+func describe(releases []*versions.Release) []versionInfo {
+	installed, err := versions.GetLocalVersions(BinaryToInstall)
+	helpers.CheckGenericError(err)
+
+	home, _ := homedir.Dir()
+	active := selectedVersion(filepath.Join(home, ".bin", "."+BinaryToInstall+"-version"), installed)
+	pinned := selectedVersion("."+BinaryToInstall+"_version", installed)
+
+	infos := make([]versionInfo, 0, len(releases))
+
+	for _, release := range releases {
+		local := findVersion(installed, release.Version)
+		info := versionInfo{
+			Version:    release.Version.String(),
+			Installed:  local != nil,
+			Active:     active != nil && sameVersion(active, release.Version),
+			Pinned:     pinned != nil && sameVersion(pinned, release.Version),
+			Prerelease: release.Stability() != versions.StabilityStable,
+		}
+
+		if !release.Published.IsZero() {
+			published := release.Published.UTC()
+			info.ReleaseDate = &published
+		}
+
+		if local != nil {
+			info.Path = installPath(local)
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// withCachedDetails fills in what the cached list of versions knows of the
+// installed releases, like when they were released, without fetching it.
+func withCachedDetails(releases []*versions.Release) []*versions.Release {
+	source, ok := versionSource.(*versions.Cached)
+	if !ok {
+		return releases
+	}
+
+	cached, ok := source.Cached()
+	if !ok {
+		return releases
+	}
+
+	for _, release := range releases {
+		for _, remote := range cached {
+			if sameVersion(remote.Version, release.Version) {
+				release.Prerelease = remote.Prerelease
+				release.Published = remote.Published
+
+				break
+			}
+		}
+	}
+
+	return releases
+}
+
+// selectedVersion returns the installed version the version file selects,
+// resolving constraints like the wrapper. Channels are resolved against the
+// installed versions too, without reading upstream. auto, or a missing file,
+// select none.
+func selectedVersion(file string, installed []*version.Version) *version.Version {
+	data, err := os.ReadFile(file) // nolint: gosec
+	if err != nil {
+		return nil
+	}
+
+	expr := strings.TrimSpace(string(data))
+	if expr == "" || expr == "auto" {
+		return nil
+	}
+
+	if versions.IsExact(expr) {
+		v, _ := version.NewVersion(expr)
+		return v
+	}
+
+	v, err := versions.Resolve(expr, installed)
+	if err != nil {
+		return nil
+	}
+
+	return v
+}
+
+// installPath returns where the installed version v is, named as installed.
+func installPath(v *version.Version) string {
+	home, _ := homedir.Dir()
+	path, _ := filepath.Abs(fmt.Sprintf("%s/.bin/%s-v%s", home, BinaryToInstall, v.Original()))
+
+	if runtime.GOOS == "windows" {
+		path += windowsSuffix
+	}
+
+	return path
+}
+
+func sameVersion(a *version.Version, b *version.Version) bool {
+	return a.Equal(b) && a.Metadata() == b.Metadata()
+}
+
+func findVersion(list []*version.Version, v *version.Version) *version.Version {
+	for _, candidate := range list {
+		if sameVersion(candidate, v) {
+			return candidate
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	if testing.Verbose() {
+		logging.Setup("debug")
+	} else {
+		logging.Setup("error")
+	}
+
+	// Every test has its own home
+	homedir.DisableCache = true
+
+	os.Exit(m.Run())
+}
+
+// testHome sets up an empty home and working directory for kubectl, with the
+// versions given installed, and returns the home.
+func testHome(t *testing.T, installed ...string) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	previous := BinaryToInstall
+	BinaryToInstall = "kubectl"
+
+	t.Cleanup(func() { BinaryToInstall = previous })
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".bin"), 0750))
+
+	for _, v := range installed {
+		require.NoError(t, os.WriteFile(filepath.Join(home, ".bin", "kubectl-v"+v), nil, 0600))
+	}
+
+	return home
+}
+
+// writeVersionFiles selects active with use and pins pinned in the working
+// directory, unless they're empty.
+func writeVersionFiles(t *testing.T, home string, active string, pinned string) {
+	t.Helper()
+
+	if active != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(home, ".bin", ".kubectl-version"), []byte(active+"\n"), 0600))
+	}
+
+	if pinned != "" {
+		require.NoError(t, os.WriteFile(".kubectl_version", []byte(pinned+"\n"), 0600))
+	}
+}
+
+func testReleases(t *testing.T, vs ...string) []*versions.Release {
+	t.Helper()
+
+	releases := make([]*versions.Release, 0, len(vs))
+
+	for _, v := range vs {
+		releases = append(releases, &versions.Release{Version: version.Must(version.NewVersion(v))})
+	}
+
+	return releases
+}
+
+// captureStdout returns what f prints.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+
+	f()
+	require.NoError(t, w.Close())
+
+	return <-output
+}
+
+func TestSelectedVersion(t *testing.T) {
+	var flagtests = []struct {
+		testName string
+		content  *string
+		expected string
+	}{
+		{"missing file", nil, ""},
+		{"empty file", ptr(""), ""},
+		{"auto", ptr("auto\n"), ""},
+		{"exact", ptr("1.30.2\n"), "1.30.2"},
+		{"exact not installed", ptr("1.28.0"), "1.28.0"},
+		{"build metadata", ptr("1.30.0+k3s1"), "1.30.0+k3s1"},
+		{"constraint", ptr("1.30"), "1.30.2"},
+		{"constraint not installed", ptr("~> 1.31"), ""},
+		{"channel", ptr("stable-1.29"), "1.29.6"},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			testHome(t)
+
+			installed := []*version.Version{
+				version.Must(version.NewVersion("1.29.6")),
+				version.Must(version.NewVersion("1.30.0+k3s1")),
+				version.Must(version.NewVersion("1.30.2")),
+			}
+
+			if tt.content != nil {
+				require.NoError(t, os.WriteFile(".kubectl_version", []byte(*tt.content), 0600))
+			}
+
+			selected := selectedVersion(".kubectl_version", installed)
+			if tt.expected == "" {
+				assert.Nil(t, selected)
+				return
+			}
+
+			require.NotNil(t, selected)
+			assert.Equal(t, tt.expected, selected.String())
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestDescribe(t *testing.T) {
+	home := testHome(t, "1.29.6", "1.30.0+k3s1", "1.30.2")
+	writeVersionFiles(t, home, "1.30", "1.30.0+k3s1")
+
+	published := time.Date(2024, 6, 11, 15, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	releases := testReleases(t, "1.31.0-rc.1", "1.30.2", "1.30.0+k3s1", "1.30.0", "1.29.6")
+	releases[1].Published = published
+
+	utc := published.UTC()
+	bin := filepath.Join(home, ".bin", "kubectl-v")
+
+	assert.Equal(t, []versionInfo{
+		{Version: "1.31.0-rc.1", Prerelease: true},
+		{Version: "1.30.2", Installed: true, Active: true, ReleaseDate: &utc, Path: bin + "1.30.2"},
+		{Version: "1.30.0+k3s1", Installed: true, Pinned: true, Path: bin + "1.30.0+k3s1"},
+		{Version: "1.30.0"},
+		{Version: "1.29.6", Installed: true, Path: bin + "1.29.6"},
+	}, describe(releases))
+}
+
+func TestRemoteLabels(t *testing.T) {
+	released := time.Now().Add(-3 * 24 * time.Hour)
+
+	labels := remoteLabels([]versionInfo{
+		{Version: "1.30.0+k3s1", Installed: true},
+		{Version: "1.30.0"},
+		{Version: "1.30.2", Installed: true, Active: true, ReleaseDate: &released},
+	})
+
+	assert.Equal(t, []string{
+		"1.30.0+k3s1  installed",
+		"1.30.0",
+		"1.30.2       installed, active  " + released.Format(time.DateOnly) + " (3 days ago)",
+	}, labels)
+}
+
+func TestWithCachedDetails(t *testing.T) {
+	testHome(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("page") != "1" {
+			_, _ = rw.Write([]byte(`[]`))
+			return
+		}
+
+		_, _ = rw.Write([]byte(`[
+			{"tag_name": "v1.31.0-rc.1", "prerelease": true, "published_at": "2024-07-30T00:00:00Z"},
+			{"tag_name": "v1.30.2", "published_at": "2024-06-11T00:00:00Z"},
+			{"tag_name": "v1.30.0", "published_at": "2024-04-17T00:00:00Z"}
+		]`))
+	}))
+
+	defer server.Close()
+
+	source, err := versions.NewSource(versions.SourceGitHub, server.URL+"/?page=", nil)
+	require.NoError(t, err)
+
+	previous := versionSource
+
+	defer func() { versionSource = previous }()
+
+	// Nothing is cached until the versions are listed
+	versionSource = &versions.Cached{Source: source, Tool: "kubectl", Origin: server.URL, TTL: time.Hour}
+	releases := withCachedDetails(testReleases(t, "1.30.2"))
+	assert.True(t, releases[0].Published.IsZero())
+
+	_, err = versions.GetReleases(versionSource)
+	require.NoError(t, err)
+
+	releases = withCachedDetails(testReleases(t, "1.31.0-rc.1", "1.30.2", "1.30.0+k3s1", "1.29.6"))
+
+	assert.True(t, releases[0].Prerelease)
+	assert.Equal(t, time.Date(2024, 7, 30, 0, 0, 0, 0, time.UTC), releases[0].Published.UTC())
+	assert.False(t, releases[1].Prerelease)
+	assert.Equal(t, time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC), releases[1].Published.UTC())
+	// The local build isn't the upstream release
+	assert.True(t, releases[2].Published.IsZero())
+	assert.True(t, releases[3].Published.IsZero())
+
+	// Sources that aren't cached have no details to give
+	versionSource = source
+	releases = withCachedDetails(testReleases(t, "1.30.2"))
+	assert.True(t, releases[0].Published.IsZero())
+}
+
+func TestPrintReleases(t *testing.T) {
+	home := testHome(t, "1.30.2")
+	writeVersionFiles(t, home, "1.30.2", "")
+
+	releases := testReleases(t, "1.30.2", "1.29.6")
+	releases[0].Published = time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)
+
+	printed := func(output string) string {
+		previous := listOutput
+		listOutput = output
+
+		defer func() { listOutput = previous }()
+
+		return captureStdout(t, func() { printReleases(releases) })
+	}
+
+	// The fields scripts depend on, omitting the unknown ones
+	expected := []map[string]any{
+		{
+			"version": "1.30.2", "installed": true, "active": true, "pinned": false, "prerelease": false,
+			"release_date": "2024-06-11T00:00:00Z", "path": filepath.Join(home, ".bin", "kubectl-v1.30.2"),
+		},
+		{"version": "1.29.6", "installed": false, "active": false, "pinned": false, "prerelease": false},
+	}
+
+	t.Run("versions", func(t *testing.T) {
+		assert.Equal(t, "1.30.2\n1.29.6\n", printed(""))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var list struct {
+			Tool     string           `json:"tool"`
+			Versions []map[string]any `json:"versions"`
+		}
+
+		require.NoError(t, json.Unmarshal([]byte(printed(outputJSON)), &list))
+		assert.Equal(t, "kubectl", list.Tool)
+		assert.Equal(t, expected, list.Versions)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var list struct {
+			Tool     string           `yaml:"tool"`
+			Versions []map[string]any `yaml:"versions"`
+		}
+
+		require.NoError(t, yaml.Unmarshal([]byte(printed(outputYAML)), &list))
+		assert.Equal(t, "kubectl", list.Tool)
+		require.Len(t, list.Versions, 2)
+
+		// YAML decodes the timestamps as such
+		assert.Equal(t, releases[0].Published, list.Versions[0]["release_date"])
+		list.Versions[0]["release_date"] = expected[0]["release_date"]
+
+		assert.Equal(t, expected, list.Versions)
+	})
+
+	t.Run("table", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSuffix(printed(outputTable), "\n"), "\n")
+		require.Len(t, lines, 3)
+
+		assert.Equal(t, []string{"VERSION", "INSTALLED", "ACTIVE", "PINNED", "PRERELEASE", "RELEASED", "PATH"},
+			strings.Fields(lines[0]))
+		assert.Equal(t, []string{"1.30.2", "true", "true", "false", "false", "2024-06-11",
+			filepath.Join(home, ".bin", "kubectl-v1.30.2")}, strings.Fields(lines[1]))
+		assert.Equal(t, []string{"1.29.6", "false", "false", "false", "false", "-"}, strings.Fields(lines[2]))
+	})
+}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
@@ -23,29 +28,55 @@ func remote(cmd *cobra.Command, args []string) {
 
 	var (
 		versionList []*version.Version
+		releases    []*versions.Release
 		err         error
-		allReleases bool
-		allVersions bool
 	)
 
 	logging.Debug("list-remote called", "args", args)
 
-	versionList, err = versions.GetRemoteVersions(VersionsAPI)
-	helpers.CheckGenericError(err)
-	allReleases, err = cmd.Flags().GetBool("all-releases")
-	helpers.CheckGenericError(err)
-	allVersions, err = cmd.Flags().GetBool("all-versions")
+	channel, limit := listFilter(cmd)
+
+	// The releases carry the prerelease and draft flags of the sources that have them
+	releases, err = versions.GetReleases(versionSource)
 	helpers.CheckGenericError(err)
-	versionList, err = versions.SortVersions(versionList, allReleases, allVersions)
+
+	// Fetching the dates is only worth it when they're asked for, otherwise
+	// the cached ones are shown if there are any
+	releases, dated := withReleaseDates(releases, since != "" || listOutput != "")
+	if since != "" && !dated {
+		helpers.CheckGenericError(fmt.Errorf("the versions source of %s has no release dates, --since can't be used",
+			BinaryToInstall))
+	}
+
+	releases, err = versions.FilterReleases(releases, channel)
 	helpers.CheckGenericError(err)
 
+	releases = filterMinor(releases)
+
+	if flavor != "" {
+		releases, err = versions.FilterFlavor(releases, flavor)
+		helpers.CheckGenericError(err)
+	}
+
+	releases = filterRemote(releases)
+	releases = sortList(releases, limit)
+
+	// Structured output is for scripts, it's never interactive
+	if listOutput != "" {
+		printReleases(releases)
+		return
+	}
+
+	versionList = versions.ReleaseVersions(releases)
+	labels := remoteLabels(describe(releases))
+
 	// Interactive selection via embedded fuzzy finder. On cancel, fall back to printing all.
 	items := make([]string, 0, len(versionList))
 	for _, v := range versionList {
 		items = append(items, v.String())
 	}
 
-	if sel, err := fzf.Select(items, "Select remote version> "); err == nil && sel != "" {
+	if sel, err := fzf.SelectLabeled(items, labels, "Select remote version> "); err == nil && sel != "" {
 		fmt.Println(sel)
 		return
 	} else if err == fzf.ErrNonInteractive {
@@ -53,9 +84,194 @@ func remote(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	versions.PrintVersions(versionList)
+	for _, label := range labels {
+		fmt.Println(label)
+	}
+}
+
+// withReleaseDates fills in when the releases were published from the releases
+// of the tool, for sources that don't say, like the markers of kubectl. They're
+// taken from the cache, and only fetched if fetch and some are missing. It
+// tells if the releases have dates.
+func withReleaseDates(releases []*versions.Release, fetch bool) ([]*versions.Release, bool) {
+	for _, release := range releases {
+		if !release.Published.IsZero() {
+			return releases, true
+		}
+	}
+
+	published := releaseDates(cachedReleases(datesSource))
+
+	if fetch && missingDates(releases, published) {
+		dated, err := versions.GetReleases(datesSource)
+		if err != nil {
+			logging.Debug("could not get the release dates", "error", err)
+		} else {
+			published = releaseDates(dated)
+		}
+	}
+
+	for _, release := range releases {
+		release.Published = published[release.Version.String()]
+	}
+
+	return releases, len(published) > 0
+}
+
+// releaseDates returns when every release of releases was published, by its
+// version, leaving out the ones that don't say.
+func releaseDates(releases []*versions.Release) map[string]time.Time {
+	published := make(map[string]time.Time, len(releases))
+
+	for _, release := range releases {
+		if !release.Published.IsZero() {
+			published[release.Version.String()] = release.Published
+		}
+	}
+
+	return published
+}
+
+// missingDates tells if any of releases isn't in published.
+func missingDates(releases []*versions.Release, published map[string]time.Time) bool {
+	for _, release := range releases {
+		if _, ok := published[release.Version.String()]; !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cachedReleases returns the releases source has in the cache, however old
+// they are, without fetching them.
+func cachedReleases(source versions.VersionSource) []*versions.Release {
+	cached, ok := source.(*versions.Cached)
+	if !ok {
+		return nil
+	}
+
+	releases, _ := cached.Cached()
+
+	return releases
+}
+
+// filterRemote keeps the releases published in the time asked with --since,
+// and the installed or not installed ones if asked.
+func filterRemote(releases []*versions.Release) []*versions.Release {
+	var after time.Time
+
+	if since != "" {
+		age, err := parseAge(since)
+		helpers.CheckGenericError(err)
+
+		after = time.Now().Add(-age)
+	}
+
+	installed, err := versions.GetLocalVersions(BinaryToInstall)
+	helpers.CheckGenericError(err)
+
+	filtered := make([]*versions.Release, 0, len(releases))
+
+	for _, release := range releases {
+		// The releases without a date can't tell how old they are
+		if !after.IsZero() && (release.Published.IsZero() || release.Published.Before(after)) {
+			continue
+		}
+
+		isInstalled := findVersion(installed, release.Version) != nil
+		if (onlyInstalled && !isInstalled) || (notInstalled && isInstalled) {
+			continue
+		}
+
+		filtered = append(filtered, release)
+	}
+
+	return filtered
+}
+
+// parseAge parses an age like 90d or 2w, or any duration like 36h.
+func parseAge(age string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} // nolint: mnd
+
+	for suffix, unit := range units {
+		if n, err := strconv.Atoi(strings.TrimSuffix(age, suffix)); err == nil && strings.HasSuffix(age, suffix) && n >= 0 {
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(age)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid age %q, must be like 90d, 2w or 36h", age)
+	}
+
+	return duration, nil
 }
 
+// remoteLabels returns a line for every version, telling if it's installed or
+// active and when it was released, aligned in columns.
+func remoteLabels(infos []versionInfo) []string {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0) // nolint: mnd
+
+	for _, info := range infos {
+		var marks []string
+
+		if info.Installed {
+			marks = append(marks, "installed")
+		}
+
+		if info.Active {
+			marks = append(marks, "active")
+		}
+
+		released := ""
+		if info.ReleaseDate != nil {
+			released = fmt.Sprintf("%s (%s)", info.ReleaseDate.Format(time.DateOnly), humanAge(time.Since(*info.ReleaseDate)))
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", info.Version, strings.Join(marks, ", "), released)
+	}
+
+	_ = w.Flush()
+
+	labels := make([]string, 0, len(infos))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if line != "" {
+			labels = append(labels, strings.TrimRight(line, " "))
+		}
+	}
+
+	return labels
+}
+
+// humanAge returns how long ago something happened, roughly, like 3 days ago.
+func humanAge(age time.Duration) string {
+	days := int(age.Hours() / 24) // nolint: mnd
+
+	switch {
+	case days < 1:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	case days < 60: // nolint: mnd
+		return fmt.Sprintf("%d days ago", days)
+	case days < 730: // nolint: mnd
+		return fmt.Sprintf("%d months ago", days/30) // nolint: mnd
+	default:
+		return fmt.Sprintf("%d years ago", days/365) // nolint: mnd
+	}
+}
+
+var (
+	flavor        string
+	since         string
+	onlyInstalled bool
+	notInstalled  bool
+)
+
 // remoteCmd represents the remote command
 var remoteCmd = &cobra.Command{
 	Use:   "remote",
@@ -64,5 +280,10 @@ var remoteCmd = &cobra.Command{
 }
 
 func init() {
+	remoteCmd.Flags().StringVar(&flavor, "flavor", "", "only list the OKD builds of a flavor, okd or scos")
+	remoteCmd.Flags().StringVar(&since, "since", "", "only list the versions released in this time, like 90d, 2w or 36h")
+	remoteCmd.Flags().BoolVar(&onlyInstalled, "installed", false, "only list the installed versions")
+	remoteCmd.Flags().BoolVar(&notInstalled, "not-installed", false, "only list the versions not installed")
+	remoteCmd.MarkFlagsMutuallyExclusive("installed", "not-installed")
 	listCmd.AddCommand(remoteCmd)
 }
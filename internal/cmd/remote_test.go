@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAge(t *testing.T) {
+	var flagtests = []struct {
+		input    string
+		expected time.Duration
+		valid    bool
+	}{
+		{"90d", 90 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"0d", 0, true},
+		{"36h", 36 * time.Hour, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"-3d", 0, false},
+		{"-2w", 0, false},
+		{"-36h", 0, false},
+		{"d", 0, false},
+		{"1.5d", 0, false},
+		{"3 days", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.input, func(t *testing.T) {
+			age, err := parseAge(tt.input)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, age)
+		})
+	}
+}
+
+func TestHumanAge(t *testing.T) {
+	const day = 24 * time.Hour
+
+	var flagtests = []struct {
+		age      time.Duration
+		expected string
+	}{
+		{0, "today"},
+		{23 * time.Hour, "today"},
+		{day, "yesterday"},
+		{day + 23*time.Hour, "yesterday"},
+		{2 * day, "2 days ago"},
+		{59 * day, "59 days ago"},
+		{60 * day, "2 months ago"},
+		{729 * day, "24 months ago"},
+		{730 * day, "2 years ago"},
+		{1200 * day, "3 years ago"},
+	}
+
+	for _, tt := range flagtests {
+		assert.Equal(t, tt.expected, humanAge(tt.age), tt.age.String())
+	}
+}
+
+func TestFilterRemote(t *testing.T) {
+	var flagtests = []struct {
+		testName      string
+		since         string
+		onlyInstalled bool
+		notInstalled  bool
+		expected      []string
+	}{
+		{"no filter", "", false, false, []string{"1.31.0", "1.30.2", "1.30.0+k3s1", "1.29.6", "1.28.0"}},
+		{"since", "30d", false, false, []string{"1.31.0", "1.30.2"}},
+		{"since in weeks", "52w", false, false, []string{"1.31.0", "1.30.2", "1.29.6"}},
+		{"installed", "", true, false, []string{"1.30.2", "1.30.0+k3s1"}},
+		{"not installed", "", false, true, []string{"1.31.0", "1.29.6", "1.28.0"}},
+		{"installed since", "30d", true, false, []string{"1.30.2"}},
+		{"not installed since", "52w", false, true, []string{"1.31.0", "1.29.6"}},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			testHome(t, "1.30.2", "1.30.0+k3s1")
+
+			previousSince, previousInstalled, previousNotInstalled := since, onlyInstalled, notInstalled
+			since, onlyInstalled, notInstalled = tt.since, tt.onlyInstalled, tt.notInstalled
+
+			defer func() {
+				since, onlyInstalled, notInstalled = previousSince, previousInstalled, previousNotInstalled
+			}()
+
+			now := time.Now()
+			releases := testReleases(t, "1.31.0", "1.30.2", "1.30.0+k3s1", "1.29.6", "1.28.0")
+			releases[0].Published = now.Add(-2 * 24 * time.Hour)
+			releases[1].Published = now.Add(-20 * 24 * time.Hour)
+			// 1.30.0+k3s1 is a local build, it has no release date
+			releases[3].Published = now.Add(-200 * 24 * time.Hour)
+			releases[4].Published = now.Add(-400 * 24 * time.Hour)
+
+			filtered := filterRemote(releases)
+
+			actual := make([]string, 0, len(filtered))
+			for _, release := range filtered {
+				actual = append(actual, release.Version.String())
+			}
+
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestWithReleaseDates(t *testing.T) {
+	var flagtests = []struct {
+		testName string
+		cached   bool
+		fetch    bool
+		limited  bool
+		dated    bool
+		requests int32
+	}{
+		{"nothing cached", false, false, false, false, 0},
+		{"cached", true, false, false, true, 0},
+		{"cached and fetched", true, true, false, true, 0},
+		{"fetched", false, true, false, true, 1},
+		// The dates are optional, the rate limit isn't waited for
+		{"rate limited", false, true, true, false, 1},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			testHome(t)
+
+			var requests int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&requests, 1)
+
+				if tt.limited {
+					rw.Header().Set("X-Ratelimit-Remaining", "0")
+					rw.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+					rw.WriteHeader(http.StatusForbidden)
+
+					return
+				}
+
+				if req.URL.Query().Get("page") != "1" {
+					_, _ = rw.Write([]byte(`[]`))
+					return
+				}
+
+				_, _ = rw.Write([]byte(`[
+					{"tag_name": "v1.30.2", "published_at": "2024-06-11T00:00:00Z"},
+					{"tag_name": "v1.29.6", "published_at": "2024-06-11T00:00:00Z"}
+				]`))
+			}))
+
+			defer server.Close()
+
+			source, err := versions.NewSource(versions.SourceGitHub, server.URL+"/?page=", nil)
+			require.NoError(t, err)
+
+			previous := datesSource
+
+			defer func() { datesSource = previous }()
+
+			datesSource = &versions.Cached{
+				Source: source, Tool: "kubectl-releases", Origin: server.URL, TTL: time.Hour, Optional: true,
+			}
+
+			if tt.cached {
+				_, err := versions.GetReleases(datesSource)
+				require.NoError(t, err)
+				atomic.StoreInt32(&requests, 0)
+			}
+
+			var (
+				releases []*versions.Release
+				dated    bool
+			)
+
+			// Nothing is printed, so structured output isn't broken
+			output := captureStdout(t, func() {
+				releases, dated = withReleaseDates(testReleases(t, "1.30.2", "1.29.6"), tt.fetch)
+			})
+
+			assert.Empty(t, output)
+			assert.Equal(t, tt.dated, dated)
+			assert.Equal(t, tt.dated, !releases[0].Published.IsZero())
+			assert.Equal(t, tt.requests, atomic.LoadInt32(&requests))
+		})
+	}
+}
+
+func TestWithReleaseDatesOfTheSource(t *testing.T) {
+	testHome(t)
+
+	previous := datesSource
+
+	defer func() { datesSource = previous }()
+
+	// Sources that have the dates are never asked for them again
+	datesSource = nil
+
+	releases := testReleases(t, "1.30.2")
+	releases[0].Published = time.Now()
+
+	_, dated := withReleaseDates(releases, true)
+	assert.True(t, dated)
+}
@@ -4,20 +4,35 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/config"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/provider"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
 	"github.com/spf13/cobra"
 )
 
-var BinaryDownloadURL string
-var BinaryDownloadURLs []string
-var VersionsAPI string
 var RootCmd = &cobra.Command{}
 var BinaryToInstall string
 var windowsSuffix string = ".exe"
 var logLevel = "info"
 var verbose bool
 
+// binaryProvider knows where BinaryToInstall is published, versionSource where
+// its versions are listed and releasesSource where its releases are published
+// with their notes, and their dates, cached in datesSource. They're set up once
+// the configuration is loaded
+var (
+	binaryProvider provider.Provider
+	versionSource  versions.VersionSource
+	releasesSource versions.VersionSource
+	datesSource    versions.VersionSource
+)
+
 func Execute() {
+	// The tool is only known now, once main has set it
+	hideOKDFlags()
+
 	if err := RootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -30,6 +45,11 @@ func init() {
 		"log-level", "info", "log level: debug, info, warn, error")
 	RootCmd.PersistentFlags().BoolVarP(&verbose,
 		"verbose", "v", false, "enable debug logging (shorthand for --log-level=debug)")
+	RootCmd.PersistentFlags().BoolVar(&versions.Refresh,
+		"refresh", false, "fetch the list of versions again instead of using the cached one")
+	RootCmd.PersistentFlags().BoolVar(&versions.Offline,
+		"offline", false, "only use the cached list of versions")
+	RootCmd.MarkFlagsMutuallyExclusive("refresh", "offline")
 
 	cobra.OnInitialize(func() {
 		if verbose {
@@ -38,5 +58,58 @@ func init() {
 
 		logging.Setup(logLevel)
 		logging.Debug("logger initialized", "level", logLevel)
+
+		loadConfig()
 	})
 }
+
+// loadConfig sets up the provider of the binary, overriding its default urls
+// with the ones set in the environment or the configuration file, if any. Tools
+// defined in the tools directory next to the configuration file are available
+// too. The versions listed are cached for the configured time.
+func loadConfig() {
+	conf, err := config.Load()
+	helpers.CheckGenericError(err)
+
+	err = provider.LoadDefinitions(provider.DefinitionsDir())
+	helpers.CheckGenericError(err)
+
+	defaults, err := provider.Defaults(BinaryToInstall)
+	helpers.CheckGenericError(err)
+
+	tool, err := conf.Resolve(BinaryToInstall, defaults)
+	helpers.CheckGenericError(err)
+
+	binaryProvider, err = provider.New(BinaryToInstall, tool)
+	helpers.CheckGenericError(err)
+
+	source, err := binaryProvider.VersionSource()
+	helpers.CheckGenericError(err)
+
+	ttl, err := conf.VersionsTTL()
+	helpers.CheckGenericError(err)
+
+	// The versions are kept in the cache, so listing them again doesn't page through the API
+	versionSource = &versions.Cached{
+		Source: source,
+		Tool:   BinaryToInstall,
+		Origin: fmt.Sprint(tool),
+		TTL:    ttl,
+	}
+
+	// The versions may be listed from elsewhere, like the markers of kubectl,
+	// but the notes are only in the releases
+	releasesSource, err = provider.NewSource(tool)
+	helpers.CheckGenericError(err)
+
+	datesSource = &versions.Cached{
+		Source:   releasesSource,
+		Tool:     BinaryToInstall + datesSuffix,
+		Origin:   fmt.Sprint(tool),
+		TTL:      ttl,
+		Optional: true,
+	}
+
+	logging.Debug("urls configured", "binaryDownloadURL", tool.BinaryDownloadURL, "versionsAPI", tool.VersionsAPI,
+		"versionsSource", tool.VersionsSource)
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 )
@@ -29,6 +30,31 @@ func use(cmd *cobra.Command, args []string) {
 
 	var version = args[0]
 
+	// Constraints and channels are recorded as is, the wrapper resolves them
+	// when it runs: channels from upstream, installing what they point to, and
+	// constraints against the installed versions
+	if versions.IsChannel(version) {
+		if resolved, err := versions.ResolveChannel(versionSource, version); err == nil {
+			fmt.Printf("%s currently resolves to %s.\n", version, resolved)
+		} else {
+			fmt.Printf("Could not resolve %s yet: %s\n", version, err)
+		}
+	} else if version != "auto" && !versions.IsExact(version) {
+		if _, err = versions.ParseConstraint(version); err != nil {
+			fmt.Printf("The version or constraint '%s' is not valid: %s\n", version, err)
+			os.Exit(1)
+		}
+
+		installed, err := versions.GetLocalVersions(BinaryToInstall)
+		helpers.CheckGenericError(err)
+
+		if resolved, err := versions.Resolve(version, installed); err == nil {
+			fmt.Printf("%s currently resolves to %s.\n", version, resolved)
+		} else {
+			fmt.Printf("No installed version matches %s yet.\n", version)
+		}
+	}
+
 	home, _ := homedir.Dir()
 	binPath := fmt.Sprintf("%s/.bin", home)
 	defaultBin := fmt.Sprintf("%s/.%s-version", binPath, BinaryToInstall)
@@ -42,7 +68,7 @@ func use(cmd *cobra.Command, args []string) {
 
 // useCmd represents the use command
 var useCmd = &cobra.Command{
-	Use:   "use",
+	Use:   "use [version|constraint|channel]",
 	Short: "Set the default version to use",
 	Run:   use,
 }
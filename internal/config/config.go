@@ -0,0 +1,218 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultVersionsCacheTTL is how long the lists of versions are cached.
+const DefaultVersionsCacheTTL = time.Hour
+
+// urlPlaceholders is the number of %s the download url templates must have:
+// version, os and arch (or version, os and version for oc).
+const urlPlaceholders = 3
+
+// Tool holds the settings of a managed tool that can be overridden, for example
+// to use an internal mirror.
+type Tool struct {
+	// BinaryDownloadURL is the template of the url of the binaries
+	BinaryDownloadURL string `yaml:"binaryDownloadURL,omitempty"`
+	// VersionsAPI is the url of the list of releases, the page number is appended
+	VersionsAPI string `yaml:"versionsAPI,omitempty"`
+	// VersionsSource is the kind of server of VersionsAPI: github, gitlab,
+	// gitea or index
+	VersionsSource string `yaml:"versionsSource,omitempty"`
+	// TagPrefix is stripped from the tags of the releases, the ones without it
+	// aren't versions of the tool
+	TagPrefix string `yaml:"tagPrefix,omitempty"`
+	// TagPattern is the regular expression the tags must match, capturing the
+	// version in its first group if it has one
+	TagPattern string `yaml:"tagPattern,omitempty"`
+}
+
+// Config is the content of the configuration file:
+//
+//	tools:
+//	  kubectl:
+//	    binaryDownloadURL: https://mirror.example.com/kubectl/v%s/bin/%s/%s/kubectl
+//	    versionsAPI: https://mirror.example.com/api/kubernetes/releases?per_page=100&page=
+//	  helm:
+//	    versionsAPI: https://gitlab.example.com/api/v4/projects/42/releases?per_page=100&page=
+//	    versionsSource: gitlab
+//	  kustomize:
+//	    tagPrefix: kustomize/
+//	versionsCacheTTL: 6h
+type Config struct {
+	Tools map[string]Tool `yaml:"tools"`
+	// VersionsCacheTTL is how long the lists of versions are cached, like 30m
+	VersionsCacheTTL string `yaml:"versionsCacheTTL,omitempty"`
+}
+
+type Error struct {
+	Err    string
+	Source string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s\nsource: %s", e.Err, e.Source)
+}
+
+// Path returns the configuration file path, $KBM_CONFIG if set or
+// $XDG_CONFIG_HOME/kbm/config.yaml otherwise.
+func Path() string {
+	if path := os.Getenv("KBM_CONFIG"); path != "" {
+		return path
+	}
+
+	base := os.Getenv("XDG_CONFIG_HOME")
+
+	if base == "" {
+		base, _ = os.UserConfigDir()
+	}
+
+	return filepath.Join(base, "kbm", "config.yaml")
+}
+
+// Load reads the configuration file. A missing file is an empty configuration.
+func Load() (*Config, error) {
+	var config Config
+
+	path := Path()
+
+	data, err := os.ReadFile(path) // nolint: gosec
+	if errors.Is(err, fs.ErrNotExist) {
+		logging.Debug("no config file found", "path", path)
+		return &config, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, &Error{err.Error(), path}
+	}
+
+	if _, err := parseTTL(config.VersionsCacheTTL); err != nil {
+		return nil, &Error{err.Error(), path}
+	}
+
+	for name, tool := range config.Tools {
+		if err := tool.validate(); err != nil {
+			return nil, &Error{fmt.Sprintf("tool %s: %s", name, err), path}
+		}
+	}
+
+	logging.Debug("config file loaded", "path", path)
+
+	return &config, nil
+}
+
+// Resolve returns the settings of the tool. Every setting is taken from the
+// environment if set (for example KBM_KUBECTL_BINARY_DOWNLOAD_URL,
+// KBM_KUBECTL_VERSIONS_API or KBM_KUBECTL_TAG_PREFIX), then from the configuration file and finally
+// from defaults.
+func (c *Config) Resolve(name string, defaults Tool) (Tool, error) {
+	tool := defaults
+	fromFile := c.Tools[name]
+
+	if fromFile.BinaryDownloadURL != "" {
+		tool.BinaryDownloadURL = fromFile.BinaryDownloadURL
+	}
+
+	if fromFile.VersionsAPI != "" {
+		tool.VersionsAPI = fromFile.VersionsAPI
+	}
+
+	if fromFile.VersionsSource != "" {
+		tool.VersionsSource = fromFile.VersionsSource
+	}
+
+	if fromFile.TagPrefix != "" {
+		tool.TagPrefix = fromFile.TagPrefix
+	}
+
+	if fromFile.TagPattern != "" {
+		tool.TagPattern = fromFile.TagPattern
+	}
+
+	prefix := "KBM_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+	if url := os.Getenv(prefix + "BINARY_DOWNLOAD_URL"); url != "" {
+		tool.BinaryDownloadURL = url
+
+		if err := tool.validate(); err != nil {
+			return tool, &Error{err.Error(), prefix + "BINARY_DOWNLOAD_URL"}
+		}
+	}
+
+	if url := os.Getenv(prefix + "VERSIONS_API"); url != "" {
+		tool.VersionsAPI = url
+	}
+
+	if source := os.Getenv(prefix + "VERSIONS_SOURCE"); source != "" {
+		tool.VersionsSource = source
+	}
+
+	if tagPrefix := os.Getenv(prefix + "TAG_PREFIX"); tagPrefix != "" {
+		tool.TagPrefix = tagPrefix
+	}
+
+	if pattern := os.Getenv(prefix + "TAG_PATTERN"); pattern != "" {
+		tool.TagPattern = pattern
+
+		if err := tool.validate(); err != nil {
+			return tool, &Error{err.Error(), prefix + "TAG_PATTERN"}
+		}
+	}
+
+	return tool, nil
+}
+
+// VersionsTTL returns how long the lists of versions are cached, taken from
+// KBM_VERSIONS_CACHE_TTL if set, then from the configuration file and finally
+// DefaultVersionsCacheTTL.
+func (c *Config) VersionsTTL() (time.Duration, error) {
+	if env := os.Getenv("KBM_VERSIONS_CACHE_TTL"); env != "" {
+		ttl, err := parseTTL(env)
+		if err != nil {
+			return 0, &Error{err.Error(), "KBM_VERSIONS_CACHE_TTL"}
+		}
+
+		return ttl, nil
+	}
+
+	return parseTTL(c.VersionsCacheTTL)
+}
+
+func parseTTL(ttl string) (time.Duration, error) {
+	if ttl == "" {
+		return DefaultVersionsCacheTTL, nil
+	}
+
+	duration, err := time.ParseDuration(ttl)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("versionsCacheTTL must be a duration like 30m or 6h: %s", ttl)
+	}
+
+	return duration, nil
+}
+
+func (t Tool) validate() error {
+	if t.BinaryDownloadURL != "" && strings.Count(t.BinaryDownloadURL, "%s") != urlPlaceholders {
+		return fmt.Errorf("binaryDownloadURL must have %d %%s placeholders: %s", urlPlaceholders, t.BinaryDownloadURL)
+	}
+
+	if _, err := regexp.Compile(t.TagPattern); err != nil {
+		return fmt.Errorf("tagPattern must be a regular expression: %w", err)
+	}
+
+	return nil
+}
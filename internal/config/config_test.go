@@ -0,0 +1,169 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	if testing.Verbose() {
+		logging.Setup("debug")
+	} else {
+		logging.Setup("error")
+	}
+
+	os.Exit(m.Run())
+}
+
+var defaults = Tool{
+	BinaryDownloadURL: "https://dl.k8s.io/release/v%s/bin/%s/%s/kubectl",
+	VersionsAPI:       "https://api.github.com/repos/kubernetes/kubernetes/releases?per_page=100&page=",
+}
+
+func writeConfig(t *testing.T, content string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	t.Setenv("KBM_CONFIG", path)
+}
+
+func TestResolve(t *testing.T) { // nolint: funlen
+	var flagtests = []struct {
+		testName string
+		config   string
+		env      map[string]string
+		expected Tool
+	}{
+		{
+			"defaults",
+			"",
+			nil,
+			defaults,
+		},
+		{
+			"config file",
+			`tools:
+  kubectl:
+    binaryDownloadURL: https://mirror.corp/k8s/v%s/%s/%s/kubectl
+  helm:
+    versionsAPI: https://mirror.corp/helm?page=
+`,
+			nil,
+			Tool{"https://mirror.corp/k8s/v%s/%s/%s/kubectl", defaults.VersionsAPI, "", "", ""},
+		},
+		{
+			"environment wins over config file",
+			`tools:
+  kubectl:
+    binaryDownloadURL: https://mirror.corp/k8s/v%s/%s/%s/kubectl
+    versionsAPI: https://mirror.corp/k8s?page=
+`,
+			map[string]string{"KBM_KUBECTL_BINARY_DOWNLOAD_URL": "https://other.corp/v%s/%s/%s/kubectl"},
+			Tool{"https://other.corp/v%s/%s/%s/kubectl", "https://mirror.corp/k8s?page=", "", "", ""},
+		},
+		{
+			"versions source",
+			`tools:
+  kubectl:
+    versionsAPI: https://gitlab.corp/api/v4/projects/42/releases?per_page=100&page=
+    versionsSource: gitlab
+`,
+			map[string]string{"KBM_KUBECTL_VERSIONS_SOURCE": "gitea"},
+			Tool{defaults.BinaryDownloadURL, "https://gitlab.corp/api/v4/projects/42/releases?per_page=100&page=", "gitea", "", ""},
+		},
+		{
+			"tags",
+			`tools:
+  kubectl:
+    tagPrefix: kubectl/
+    tagPattern: ^v(1\.\d+\.\d+)$
+`,
+			map[string]string{"KBM_KUBECTL_TAG_PREFIX": "cli/"},
+			Tool{defaults.BinaryDownloadURL, defaults.VersionsAPI, "", "cli/", `^v(1\.\d+\.\d+)$`},
+		},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			writeConfig(t, tt.config)
+
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			config, err := Load()
+			require.NoError(t, err)
+
+			actual, err := config.Resolve("kubectl", defaults)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestInvalidTemplates(t *testing.T) {
+	writeConfig(t, "tools:\n  kubectl:\n    binaryDownloadURL: https://mirror.corp/kubectl\n")
+
+	_, err := Load()
+	assert.IsType(t, &Error{}, err)
+
+	writeConfig(t, "")
+	t.Setenv("KBM_KUBECTL_BINARY_DOWNLOAD_URL", "https://mirror.corp/%s/kubectl")
+
+	config, err := Load()
+	require.NoError(t, err)
+
+	_, err = config.Resolve("kubectl", defaults)
+	assert.IsType(t, &Error{}, err)
+
+	writeConfig(t, "tools:\n  kubectl:\n    tagPattern: v(\n")
+
+	_, err = Load()
+	assert.IsType(t, &Error{}, err)
+}
+
+func TestVersionsTTL(t *testing.T) {
+	writeConfig(t, "")
+
+	config, err := Load()
+	require.NoError(t, err)
+
+	ttl, err := config.VersionsTTL()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultVersionsCacheTTL, ttl)
+
+	writeConfig(t, "versionsCacheTTL: 6h\n")
+
+	config, err = Load()
+	require.NoError(t, err)
+
+	ttl, err = config.VersionsTTL()
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, ttl)
+
+	t.Setenv("KBM_VERSIONS_CACHE_TTL", "0")
+
+	ttl, err = config.VersionsTTL()
+	require.NoError(t, err)
+	assert.Zero(t, ttl)
+
+	t.Setenv("KBM_VERSIONS_CACHE_TTL", "tomorrow")
+
+	_, err = config.VersionsTTL()
+	assert.IsType(t, &Error{}, err)
+
+	writeConfig(t, "versionsCacheTTL: -1h\n")
+
+	_, err = Load()
+	assert.IsType(t, &Error{}, err)
+}
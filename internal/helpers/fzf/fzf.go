@@ -34,3 +34,23 @@ func Select(items []string, prompt string) (string, error) {
 
 	return items[idx], nil
 }
+
+// SelectLabeled works like Select, but shows labels in the fuzzy finder in place
+// of the items, like a version with its release date. The items alone are
+// printed when stdout is not a TTY, so what's piped doesn't change.
+func SelectLabeled(items []string, labels []string, prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return Select(items, prompt)
+	}
+
+	idx, err := fuzzyfinder.Find(
+		labels,
+		func(i int) string { return labels[i] },
+		fuzzyfinder.WithPromptString(prompt),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return items[idx], nil
+}
@@ -7,7 +7,6 @@ import (
 	"net/http"
 	"os"
 	"runtime"
-	"strconv"
 	"strings"
 )
 
@@ -54,34 +53,21 @@ func FileExists(filename string) bool {
 	return !info.IsDir()
 }
 
-func GetLastPage(link string) (int, error) {
-	var (
-		minLastPage = 2
-		maxReplace  = 2
-		lastPageInt int
-		err         error
-	)
+// HumanBytes formats a number of bytes with binary units, like 12.3 MiB
+func HumanBytes(n int64) string {
+	const unit = 1024
 
-	// When there's no link, it means there's least than 100 releases
-
-	if link == "" {
-		return minLastPage, nil
-	}
-
-	link = strings.Split(link, " ")[2]
-	lastPageIndex := strings.LastIndex(link, "page=")
-	lastPageStr := strings.Replace(link[lastPageIndex+5:], ">;", "", maxReplace)
-
-	lastPageInt, err = strconv.Atoi(lastPageStr)
-	if err != nil {
-		return 0, err
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
 	}
 
-	if lastPageInt == 0 {
-		lastPageInt = minLastPage
+	div, exp := int64(unit), 0
+	for i := n / unit; i >= unit; i /= unit {
+		div *= unit
+		exp++
 	}
 
-	return lastPageInt, nil
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
 type OSArchError struct {
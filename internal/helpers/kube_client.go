@@ -100,7 +100,7 @@ func getDefaultVersion() string {
 	// If there's no kubectl, get latest
 	// If there's at least one, use that
 	if out == "" {
-		resp, err := http.Get("https://storage.googleapis.com/kubernetes-release/release/stable.txt")
+		resp, err := http.Get("https://dl.k8s.io/release/stable.txt")
 		CheckGenericError(err)
 
 		defer resp.Body.Close()
@@ -0,0 +1,170 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Link is a link of a Link header, as defined in RFC 8288, like
+// <https://api.github.com/repositories/20580498/releases?page=2>; rel="next"
+type Link struct {
+	// URL is the target as written, it may be relative to the request
+	URL string
+	// Params are the parameters by their lowercased names, only the first one
+	// counting when they're repeated
+	Params map[string]string
+}
+
+// Links are the links of a Link header, in its order.
+type Links []Link
+
+// HasRel tells if rel is one of the relation types of the link, which are
+// compared case insensitively.
+func (l Link) HasRel(rel string) bool {
+	for _, name := range strings.Fields(l.Params["rel"]) {
+		if strings.EqualFold(name, rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rel returns the first link with the relation type rel.
+func (l Links) Rel(rel string) (Link, bool) {
+	for _, link := range l {
+		if link.HasRel(rel) {
+			return link, true
+		}
+	}
+
+	return Link{}, false
+}
+
+// ParseLinks parses the value of a Link header. Links and parameters may come
+// in any order and their values be tokens or quoted strings, which may hold
+// commas and semicolons. An empty header has no links.
+func ParseLinks(header string) (Links, error) {
+	var links Links
+
+	s := header
+
+	for {
+		s = trimOWS(s)
+
+		switch {
+		case s == "":
+			return links, nil
+		case s[0] == ',':
+			// Empty elements of the list are allowed
+			s = s[1:]
+			continue
+		case s[0] != '<':
+			return nil, fmt.Errorf("invalid Link header %q: a link must start with <", header)
+		}
+
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid Link header %q: unterminated link", header)
+		}
+
+		link := Link{URL: s[1:end], Params: map[string]string{}}
+
+		var err error
+
+		if s, err = parseParams(s[end+1:], link.Params); err != nil {
+			return nil, fmt.Errorf("invalid Link header %q: %w", header, err)
+		}
+
+		links = append(links, link)
+	}
+}
+
+// parseParams parses the parameters of a link into params, returning what
+// follows them: the rest of the list or nothing.
+func parseParams(s string, params map[string]string) (string, error) {
+	for {
+		s = trimOWS(s)
+
+		if s == "" || s[0] == ',' {
+			return s, nil
+		}
+
+		if s[0] != ';' {
+			return "", fmt.Errorf("unexpected %q after a link", s[0])
+		}
+
+		name, rest := token(trimOWS(s[1:]))
+		if name == "" {
+			return "", fmt.Errorf("invalid parameter name at %q", s)
+		}
+
+		s = trimOWS(rest)
+		value := ""
+
+		if s != "" && s[0] == '=' {
+			s = trimOWS(s[1:])
+
+			var err error
+
+			if s != "" && s[0] == '"' {
+				if value, s, err = quotedString(s); err != nil {
+					return "", err
+				}
+			} else if value, s = token(s); value == "" {
+				return "", fmt.Errorf("invalid value of the parameter %s", name)
+			}
+		}
+
+		name = strings.ToLower(name)
+		if _, ok := params[name]; !ok {
+			params[name] = value
+		}
+	}
+}
+
+// token splits s after its leading token, as defined in RFC 9110.
+func token(s string) (string, string) {
+	i := 0
+
+	for i < len(s) && isTokenChar(s[i]) {
+		i++
+	}
+
+	return s[:i], s[i:]
+}
+
+func isTokenChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+	}
+}
+
+// quotedString unquotes the quoted string s starts with, returning what
+// follows it.
+func quotedString(s string) (string, string, error) {
+	var value strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return value.String(), s[i+1:], nil
+		case '\\':
+			if i++; i == len(s) {
+				return "", "", fmt.Errorf("unterminated quoted string %q", s)
+			}
+		}
+
+		value.WriteByte(s[i])
+	}
+
+	return "", "", fmt.Errorf("unterminated quoted string %q", s)
+}
+
+// trimOWS trims the optional whitespace around the elements of the header.
+func trimOWS(s string) string {
+	return strings.TrimLeft(s, " \t")
+}
@@ -0,0 +1,144 @@
+package helpers
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	if testing.Verbose() {
+		logging.Setup("debug")
+	} else {
+		logging.Setup("error")
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestParseLinks(t *testing.T) {
+	var flagtests = []struct {
+		testName string
+		input    string
+		next     string
+		last     string
+	}{
+		{
+			"github",
+			`<https://api.github.com/repositories/20580498/releases?per_page=100&page=2>; rel="next", <https://api.github.com/repositories/20580498/releases?per_page=100&page=20>; rel="last"`, // nolint: lll
+			"https://api.github.com/repositories/20580498/releases?per_page=100&page=2",
+			"https://api.github.com/repositories/20580498/releases?per_page=100&page=20",
+		},
+		{
+			"reordered rels",
+			`<https://example.com/?page=1>; rel="first", <https://example.com/?page=9>; rel="last", <https://example.com/?page=3>; rel="next"`, // nolint: lll
+			"https://example.com/?page=3",
+			"https://example.com/?page=9",
+		},
+		{
+			"last page",
+			`<https://example.com/?page=1>; rel="first", <https://example.com/?page=3>; rel="prev"`,
+			"",
+			"",
+		},
+		{
+			"tokens, several rels and no spaces",
+			`</?page=2>;rel=next,</?page=5>;title="a, b; c";rel="LAST other"`,
+			"/?page=2",
+			"/?page=5",
+		},
+		{
+			"empty elements and repeated parameters",
+			` , <https://example.com/?page=2> ; rel = "next" ; rel="last" ,`,
+			"https://example.com/?page=2",
+			"",
+		},
+		{"no header", "", "", ""},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			links, err := ParseLinks(tt.input)
+			require.NoError(t, err)
+
+			next, ok := links.Rel("next")
+			assert.Equal(t, tt.next != "", ok)
+			assert.Equal(t, tt.next, next.URL)
+
+			last, ok := links.Rel("last")
+			assert.Equal(t, tt.last != "", ok)
+			assert.Equal(t, tt.last, last.URL)
+		})
+	}
+}
+
+func TestParseInvalidLinks(t *testing.T) {
+	for _, input := range []string{
+		`https://example.com/?page=2; rel="next"`,
+		`<https://example.com/?page=2; rel="next"`,
+		`<https://example.com/?page=2>; rel="next`,
+		`<https://example.com/?page=2> rel="next"`,
+		`<https://example.com/?page=2>; ="next"`,
+		`<https://example.com/?page=2>; rel=`,
+	} {
+		_, err := ParseLinks(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func FuzzParseLinks(f *testing.F) {
+	f.Add(`<https://api.github.com/repositories/20580498/releases?per_page=100&page=2>; rel="next", <https://api.github.com/repositories/20580498/releases?per_page=100&page=20>; rel="last"`) // nolint: lll
+	f.Add(`</?page=2>;rel=next,</?page=5>;title="a, b; c";rel="LAST other"`)
+	f.Add(`<a>; title="\"quoted\" \\ value"`)
+	f.Add(` , <>;,`)
+
+	f.Fuzz(func(t *testing.T, header string) {
+		links, err := ParseLinks(header)
+		if err != nil {
+			return
+		}
+
+		for _, link := range links {
+			assert.NotContains(t, link.URL, ">")
+
+			for name := range link.Params {
+				assert.Equal(t, strings.ToLower(name), name)
+			}
+		}
+	})
+}
+
+// FuzzLinkRoundTrip checks that any url and relation types written as a Link
+// header are parsed back, whatever they hold.
+func FuzzLinkRoundTrip(f *testing.F) {
+	f.Add("https://example.com/?page=2", "next", "a, b; c")
+	f.Add("", "NEXT", `"\`)
+
+	f.Fuzz(func(t *testing.T, url string, rel string, title string) {
+		if strings.Contains(url, ">") || strings.TrimSpace(rel) == "" {
+			t.Skip()
+		}
+
+		quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		header := `<https://example.com/?page=1>; rel="first", <` + url + `>; title="` +
+			quote.Replace(title) + `"; rel="` + quote.Replace(rel) + `"`
+
+		links, err := ParseLinks(header)
+		require.NoError(t, err, header)
+		require.Len(t, links, 2)
+		assert.Equal(t, url, links[1].URL)
+		assert.Equal(t, title, links[1].Params["title"])
+		assert.Equal(t, rel, links[1].Params["rel"])
+
+		link, ok := links.Rel(strings.Fields(rel)[0])
+		assert.True(t, ok)
+		assert.Equal(t, url, link.URL)
+	})
+}
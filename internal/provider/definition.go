@@ -0,0 +1,331 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/config"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	archiveBinary = "binary"
+	archiveTarGz  = "tar.gz"
+	archiveZip    = "zip"
+)
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Definition describes a tool in a YAML file, so it can be managed without
+// writing a provider for it. The url, binaryPath and checksumURL fields are
+// Go templates with the fields of templateData:
+//
+//	name: k9s
+//	github: derailed/k9s
+//	url: https://github.com/derailed/k9s/releases/download/v{{.Version}}/k9s_{{title .OS}}_{{.Arch}}.{{.Archive}}
+//	archive: tar.gz
+//	archives:
+//	  windows: zip
+//	checksumURL: https://github.com/derailed/k9s/releases/download/v{{.Version}}/checksums.sha256
+//
+// The urls and archives maps override the defaults for a platform, keyed by
+// os/arch or just os. When asset is set, the binary is looked up first in the
+// assets of the GitHub release whose names start with it, and url is only used
+// if there's none for the platform.
+type Definition struct {
+	Name string `yaml:"name"`
+	// GitHub is the owner/repo whose releases are the versions of the tool
+	GitHub string `yaml:"github,omitempty"`
+	// VersionsAPI is the url of the versions, when they aren't in GitHub, and
+	// VersionsSource the kind of server: github, gitlab, gitea or index
+	VersionsAPI    string `yaml:"versionsAPI,omitempty"`
+	VersionsSource string `yaml:"versionsSource,omitempty"`
+	// TagPrefix and TagPattern tell which tags are versions of the tool, when
+	// the repository has others, like kustomize/ for kubernetes-sigs/kustomize
+	TagPrefix  string            `yaml:"tagPrefix,omitempty"`
+	TagPattern string            `yaml:"tagPattern,omitempty"`
+	URL        string            `yaml:"url,omitempty"`
+	URLs       map[string]string `yaml:"urls,omitempty"`
+	Asset      string            `yaml:"asset,omitempty"`
+	// Archive is the format of the artifact: tar.gz, zip or binary
+	Archive  string            `yaml:"archive,omitempty"`
+	Archives map[string]string `yaml:"archives,omitempty"`
+	// BinaryPath is the path of the binary inside the archive. When it's not
+	// set the archive is searched for a file named as the tool.
+	BinaryPath  string `yaml:"binaryPath,omitempty"`
+	ChecksumURL string `yaml:"checksumURL,omitempty"`
+	// OSNames and ArchNames rename the platforms in the templates, like darwin
+	// to mac or amd64 to x86_64
+	OSNames   map[string]string `yaml:"osNames,omitempty"`
+	ArchNames map[string]string `yaml:"archNames,omitempty"`
+}
+
+// templateData is what the templates of a definition can use.
+type templateData struct {
+	Name    string
+	Version string
+	OS      string
+	Arch    string
+	// Archive is the format of the artifact for the platform, like tar.gz
+	Archive string
+	// Ext is .exe on Windows and empty elsewhere
+	Ext string
+	// URL is the download url, only set in the checksumURL template
+	URL string
+}
+
+var templateFuncs = template.FuncMap{
+	"title": func(s string) string {
+		if s == "" {
+			return s
+		}
+
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+type definition struct {
+	def  Definition
+	urls config.Tool
+}
+
+// LoadDefinitions registers a provider for every definition in the YAML files
+// of dir. Definitions replace the built-in provider with the same name.
+func LoadDefinitions(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		var def Definition
+
+		data, err := os.ReadFile(file) // nolint: gosec
+		if err != nil {
+			return err
+		}
+
+		if err := yaml.Unmarshal(data, &def); err != nil {
+			return &Error{fmt.Sprintf("%s: %s", file, err), def.Name}
+		}
+
+		if err := RegisterDefinition(def); err != nil {
+			return &Error{fmt.Sprintf("%s: %s", file, err), def.Name}
+		}
+
+		logging.Debug("tool definition loaded", "name", def.Name, "path", file)
+	}
+
+	return nil
+}
+
+// DefinitionsDir returns where the tool definitions are read from, the tools
+// directory next to the configuration file.
+func DefinitionsDir() string {
+	return filepath.Join(filepath.Dir(config.Path()), "tools")
+}
+
+// RegisterDefinition validates the definition and registers its provider.
+func RegisterDefinition(def Definition) error {
+	if err := def.validate(); err != nil {
+		return err
+	}
+
+	api := def.VersionsAPI
+	if def.GitHub != "" {
+		api = fmt.Sprintf("https://api.github.com/repos/%s/releases?per_page=100&page=", def.GitHub)
+	}
+
+	defaults := config.Tool{
+		VersionsAPI:    api,
+		VersionsSource: def.VersionsSource,
+		TagPrefix:      def.TagPrefix,
+		TagPattern:     def.TagPattern,
+	}
+
+	Register(def.Name, defaults, func(urls config.Tool) Provider {
+		return &definition{def, urls}
+	})
+
+	return nil
+}
+
+func (d Definition) validate() error {
+	if !validName.MatchString(d.Name) {
+		return fmt.Errorf("invalid name %q", d.Name)
+	}
+
+	if (d.GitHub == "") == (d.VersionsAPI == "") {
+		return errors.New("exactly one of github or versionsAPI must be set")
+	}
+
+	if d.URL == "" && d.Asset == "" {
+		return errors.New("url or asset must be set")
+	}
+
+	if d.Asset != "" && d.GitHub == "" {
+		return errors.New("asset can only be used with github")
+	}
+
+	if d.GitHub != "" && d.VersionsSource != "" && d.VersionsSource != versions.SourceGitHub {
+		return errors.New("versionsSource can only be used with versionsAPI")
+	}
+
+	if _, err := NewSource(config.Tool{VersionsAPI: d.VersionsAPI, VersionsSource: d.VersionsSource,
+		TagPrefix: d.TagPrefix, TagPattern: d.TagPattern}); err != nil {
+		return err
+	}
+
+	archives := append([]string{d.Archive}, mapValues(d.Archives)...)
+	for _, archive := range archives {
+		switch archive {
+		case "", archiveBinary, archiveTarGz, archiveZip:
+		default:
+			return fmt.Errorf("invalid archive %q, must be one of tar.gz, zip or binary", archive)
+		}
+	}
+
+	templates := append([]string{d.URL, d.BinaryPath, d.ChecksumURL}, mapValues(d.URLs)...)
+	for _, text := range templates {
+		if _, err := template.New(d.Name).Funcs(templateFuncs).Parse(text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mapValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+
+	for _, v := range m {
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// forPlatform returns the value of m for the platform, looking for os/arch
+// first and then os, or def if there's none.
+func forPlatform(m map[string]string, def string, platform *helpers.OSArch) string {
+	if v, ok := m[platform.String()]; ok {
+		return v
+	}
+
+	if v, ok := m[platform.OS]; ok {
+		return v
+	}
+
+	return def
+}
+
+func (d *definition) data(version string, platform *helpers.OSArch) templateData {
+	data := templateData{
+		Name:    d.def.Name,
+		Version: version,
+		OS:      platform.OS,
+		Arch:    platform.Arch,
+		Archive: forPlatform(d.def.Archives, d.def.Archive, platform),
+	}
+
+	if name, ok := d.def.OSNames[platform.OS]; ok {
+		data.OS = name
+	}
+
+	if name, ok := d.def.ArchNames[platform.Arch]; ok {
+		data.Arch = name
+	}
+
+	if data.Archive == "" {
+		data.Archive = archiveBinary
+	}
+
+	if platform.IsWindows() {
+		data.Ext = ".exe"
+	}
+
+	return data
+}
+
+// render executes a template that was already validated. Execution can still
+// fail, for example with an unknown field, so the error is logged and an empty
+// string returned.
+func (d *definition) render(text string, data templateData) string {
+	var buf bytes.Buffer
+
+	err := template.Must(template.New(d.def.Name).Funcs(templateFuncs).Parse(text)).Execute(&buf, data)
+	if err != nil {
+		logging.Error("could not render the template of the tool definition", "name", d.def.Name, "error", err)
+		return ""
+	}
+
+	return buf.String()
+}
+
+func (d *definition) Name() string {
+	return d.def.Name
+}
+
+func (d *definition) VersionsAPI() string {
+	return d.urls.VersionsAPI
+}
+
+func (d *definition) VersionSource() (versions.VersionSource, error) {
+	return NewSource(d.urls)
+}
+
+// DownloadURL renders the url template of the platform. A mirror url set in
+// the configuration keeps the %s placeholders of the built-in providers for
+// version, os and arch.
+func (d *definition) DownloadURL(version string, platform *helpers.OSArch) string {
+	data := d.data(version, platform)
+
+	if d.urls.BinaryDownloadURL != "" {
+		return fmt.Sprintf(d.urls.BinaryDownloadURL, version, data.OS, data.Arch)
+	}
+
+	return d.render(forPlatform(d.def.URLs, d.def.URL, platform), data)
+}
+
+// ChecksumURL is empty when the definition doesn't have one.
+func (d *definition) ChecksumURL(version string, platform *helpers.OSArch) string {
+	if d.def.ChecksumURL == "" {
+		return ""
+	}
+
+	data := d.data(version, platform)
+	data.URL = d.DownloadURL(version, platform)
+
+	return d.render(d.def.ChecksumURL, data)
+}
+
+// AssetName returns the asset prefix of the definition. It's empty when a
+// mirror url is configured, so the binaries are downloaded from the mirror.
+func (d *definition) AssetName() string {
+	if d.urls.BinaryDownloadURL != "" {
+		return ""
+	}
+
+	return d.def.Asset
+}
+
+func (d *definition) BinaryPath(platform *helpers.OSArch) string {
+	data := d.data("", platform)
+
+	if data.Archive == archiveBinary || d.def.BinaryPath == "" {
+		return ""
+	}
+
+	return d.render(d.def.BinaryPath, data)
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/config"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	if testing.Verbose() {
+		logging.Setup("debug")
+	} else {
+		logging.Setup("error")
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestLoadDefinitions(t *testing.T) { // nolint: funlen
+	var (
+		linux   = &helpers.OSArch{OS: "linux", Arch: "amd64"}
+		darwin  = &helpers.OSArch{OS: "darwin", Arch: "arm64"}
+		windows = &helpers.OSArch{OS: "windows", Arch: "amd64"}
+	)
+
+	// The examples shipped with the repository must stay valid
+	require.NoError(t, LoadDefinitions(filepath.Join("..", "..", "examples", "tools")))
+
+	var flagtests = []struct {
+		testName    string
+		provider    string
+		version     string
+		platform    *helpers.OSArch
+		downloadURL string
+		checksumURL string
+		binaryPath  string
+	}{
+		{
+			"kind linux", "kind", "0.23.0", linux,
+			"https://github.com/kubernetes-sigs/kind/releases/download/v0.23.0/kind-linux-amd64",
+			"https://github.com/kubernetes-sigs/kind/releases/download/v0.23.0/kind-linux-amd64.sha256sum",
+			"",
+		},
+		{
+			"k9s darwin", "k9s", "0.32.5", darwin,
+			"https://github.com/derailed/k9s/releases/download/v0.32.5/k9s_Darwin_arm64.tar.gz",
+			"https://github.com/derailed/k9s/releases/download/v0.32.5/checksums.sha256",
+			"",
+		},
+		{
+			"k9s windows", "k9s", "0.32.5", windows,
+			"https://github.com/derailed/k9s/releases/download/v0.32.5/k9s_Windows_amd64.zip",
+			"https://github.com/derailed/k9s/releases/download/v0.32.5/checksums.sha256",
+			"",
+		},
+		{
+			"flux windows", "flux", "2.3.0", windows,
+			"https://github.com/fluxcd/flux2/releases/download/v2.3.0/flux_2.3.0_windows_amd64.zip",
+			"https://github.com/fluxcd/flux2/releases/download/v2.3.0/flux_2.3.0_checksums.txt",
+			"flux.exe",
+		},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			p, err := New(tt.provider, config.Tool{})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.provider, p.Name())
+			assert.Equal(t, tt.downloadURL, p.DownloadURL(tt.version, tt.platform))
+			assert.Equal(t, tt.checksumURL, p.ChecksumURL(tt.version, tt.platform))
+			assert.Equal(t, tt.binaryPath, p.BinaryPath(tt.platform))
+		})
+	}
+
+	defaults, err := Defaults("stern")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.github.com/repos/stern/stern/releases?per_page=100&page=", defaults.VersionsAPI)
+
+	p, err := New("kustomize", config.Tool{})
+	require.NoError(t, err)
+
+	source, err := p.VersionSource()
+	require.NoError(t, err)
+	require.IsType(t, &versions.GitHub{}, source)
+	assert.Equal(t, "kustomize/", source.(*versions.GitHub).Tags.Prefix)
+}
+
+func TestDefinitionPlatformOverrides(t *testing.T) {
+	require.NoError(t, RegisterDefinition(Definition{
+		Name:        "tool",
+		VersionsAPI: "https://index.corp/tool/versions",
+		URL:         "https://dl.corp/tool/{{.Version}}/tool-{{.OS}}-{{.Arch}}{{.Ext}}",
+		URLs:        map[string]string{"darwin/arm64": "https://dl.corp/tool/{{.Version}}/tool-universal"},
+		OSNames:     map[string]string{"darwin": "mac"},
+		ArchNames:   map[string]string{"amd64": "x86_64"},
+	}))
+
+	p, err := New("tool", config.Tool{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://dl.corp/tool/1.0.0/tool-universal",
+		p.DownloadURL("1.0.0", &helpers.OSArch{OS: "darwin", Arch: "arm64"}))
+	assert.Equal(t, "https://dl.corp/tool/1.0.0/tool-mac-x86_64",
+		p.DownloadURL("1.0.0", &helpers.OSArch{OS: "darwin", Arch: "amd64"}))
+	assert.Equal(t, "https://dl.corp/tool/1.0.0/tool-windows-x86_64.exe",
+		p.DownloadURL("1.0.0", &helpers.OSArch{OS: "windows", Arch: "amd64"}))
+	assert.Empty(t, p.ChecksumURL("1.0.0", &helpers.OSArch{OS: "linux", Arch: "amd64"}))
+	assert.Equal(t, "https://index.corp/tool/versions", p.VersionsAPI())
+
+	// A mirror keeps the placeholders of the built-in providers
+	p, err = New("tool", config.Tool{BinaryDownloadURL: "https://mirror.corp/tool/%s/%s/%s/tool"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://mirror.corp/tool/1.0.0/mac/x86_64/tool",
+		p.DownloadURL("1.0.0", &helpers.OSArch{OS: "darwin", Arch: "amd64"}))
+}
+
+func TestInvalidDefinitions(t *testing.T) {
+	var flagtests = []struct {
+		testName string
+		content  string
+	}{
+		{"invalid name", "name: My Tool\ngithub: a/b\nurl: https://x\n"},
+		{"no versions", "name: tool\nurl: https://x\n"},
+		{"both versions", "name: tool\ngithub: a/b\nversionsAPI: https://x\nurl: https://x\n"},
+		{"no url", "name: tool\ngithub: a/b\n"},
+		{"asset without github", "name: tool\nversionsAPI: https://x\nasset: tool\n"},
+		{"invalid archive", "name: tool\ngithub: a/b\nurl: https://x\narchive: rar\n"},
+		{"invalid template", "name: tool\ngithub: a/b\nurl: https://x/{{.Version\n"},
+		{"invalid tag pattern", "name: tool\ngithub: a/b\nurl: https://x\ntagPattern: v(\n"},
+		{"invalid yaml", "name: [tool\n"},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "tool.yaml"), []byte(tt.content), 0600))
+
+			err := LoadDefinitions(dir)
+			assert.IsType(t, &Error{}, err)
+		})
+	}
+}
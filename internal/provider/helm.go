@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/config"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+)
+
+// helm is published in get.helm.sh as a tar.gz archive, or a zip one for
+// Windows, with a .sha256sum file next to it.
+type helm struct {
+	base
+}
+
+func init() {
+	Register("helm", config.Tool{
+		BinaryDownloadURL: "https://get.helm.sh/helm-v%s-%s-%s",
+		VersionsAPI:       "https://api.github.com/repos/helm/helm/releases?per_page=100&page=",
+		TagPattern:        versionTagPattern,
+	}, func(urls config.Tool) Provider {
+		return &helm{base{"helm", urls}}
+	})
+}
+
+func (p *helm) DownloadURL(version string, platform *helpers.OSArch) string {
+	url := fmt.Sprintf(p.urls.BinaryDownloadURL, version, platform.OS, platform.Arch)
+
+	if platform.IsWindows() {
+		return url + ".zip"
+	}
+
+	return url + ".tar.gz"
+}
+
+func (p *helm) ChecksumURL(version string, platform *helpers.OSArch) string {
+	return p.DownloadURL(version, platform) + ".sha256sum"
+}
+
+// BinaryPath is inside an os-arch directory.
+func (p *helm) BinaryPath(platform *helpers.OSArch) string {
+	path := fmt.Sprintf("%s-%s/helm", platform.OS, platform.Arch)
+
+	if platform.IsWindows() {
+		path += ".exe"
+	}
+
+	return path
+}
+
+// AssetName is empty, the GitHub releases of helm only have signatures.
+func (p *helm) AssetName() string {
+	return ""
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/config"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
+)
+
+// kubectl is published as a plain binary in dl.k8s.io, with a .sha256 file
+// next to it.
+type kubectl struct {
+	base
+}
+
+func init() {
+	Register("kubectl", config.Tool{
+		BinaryDownloadURL: "https://dl.k8s.io/release/v%s/bin/%s/%s/kubectl",
+		VersionsAPI:       "https://api.github.com/repos/kubernetes/kubernetes/releases?per_page=100&page=",
+		TagPattern:        versionTagPattern,
+	}, func(urls config.Tool) Provider {
+		return &kubectl{base{"kubectl", urls}}
+	})
+}
+
+func (p *kubectl) DownloadURL(version string, platform *helpers.OSArch) string {
+	url := fmt.Sprintf(p.urls.BinaryDownloadURL, version, platform.OS, platform.Arch)
+
+	if platform.IsWindows() {
+		url += ".exe"
+	}
+
+	return url
+}
+
+func (p *kubectl) ChecksumURL(version string, platform *helpers.OSArch) string {
+	return p.DownloadURL(version, platform) + ".sha256"
+}
+
+// VersionSource lists the versions from the markers next to the binaries, with
+// GitHub as fallback, unless another source is configured.
+func (p *kubectl) VersionSource() (versions.VersionSource, error) {
+	source, err := p.base.VersionSource()
+	if err != nil || p.urls.VersionsSource != "" {
+		return source, err
+	}
+
+	return versions.Fallback{&versions.Markers{Endpoint: p.markersURL()}, source}, nil
+}
+
+// markersURL is the release directory of the download url, so a mirror of
+// dl.k8s.io is used for the markers too.
+func (p *kubectl) markersURL() string {
+	url := p.urls.BinaryDownloadURL
+
+	if i := strings.Index(url, "v%s"); i >= 0 {
+		return url[:i]
+	}
+
+	return "https://dl.k8s.io/release/"
+}
+
+func (p *kubectl) BinaryPath(platform *helpers.OSArch) string {
+	return ""
+}
+
+// AssetName is empty, the GitHub releases of kubernetes don't have binaries.
+func (p *kubectl) AssetName() string {
+	return ""
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/config"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+)
+
+// oc is published in the OKD GitHub releases as a tar.gz archive, or a zip one
+// for Windows, with a single sha256sum.txt file for all the release assets.
+type oc struct {
+	base
+}
+
+const ocDownloadURL = "https://github.com/openshift/okd/releases/download/%s/openshift-client-%s-%s.tar.gz"
+
+// ocTagPattern matches the tags of the OKD builds, like
+// 4.15.0-0.okd-2024-03-10-010116 or 4.16.0-okd-scos.ec.1.
+const ocTagPattern = `^(\d+\.\d+\.\d+-(?:0\.)?okd[-.].+)$`
+
+func init() {
+	Register("oc", config.Tool{
+		BinaryDownloadURL: ocDownloadURL,
+		VersionsAPI:       "https://api.github.com/repos/openshift/okd/releases?per_page=100&page=",
+		TagPattern:        ocTagPattern,
+	}, func(urls config.Tool) Provider {
+		return &oc{base{"oc", urls}}
+	})
+}
+
+// DownloadURL has the version twice, in the release and in the asset name.
+func (p *oc) DownloadURL(version string, platform *helpers.OSArch) string {
+	var (
+		url  = p.urls.BinaryDownloadURL
+		goos = platform.OS
+	)
+
+	// OpenShift use different naming for macOS
+	if platform.IsDarwin() {
+		goos = "mac"
+	} else if platform.IsWindows() {
+		// They also use zip for Windows
+		url = strings.Replace(url, ".tar.gz", ".zip", 1)
+	}
+
+	return fmt.Sprintf(url, version, goos, version)
+}
+
+func (p *oc) ChecksumURL(version string, platform *helpers.OSArch) string {
+	url := p.DownloadURL(version, platform)
+
+	return url[:strings.LastIndex(url, "/")+1] + "sha256sum.txt"
+}
+
+func (p *oc) BinaryPath(platform *helpers.OSArch) string {
+	if platform.IsWindows() {
+		return "oc.exe"
+	}
+
+	return "oc"
+}
+
+// AssetName is empty when a mirror is used, so the binaries come from it and
+// not from GitHub.
+func (p *oc) AssetName() string {
+	if p.urls.BinaryDownloadURL != ocDownloadURL {
+		return ""
+	}
+
+	return "openshift-client"
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/config"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
+)
+
+// Provider knows where a tool is published and how its artifacts are built.
+type Provider interface {
+	// Name returns the name of the binary, like kubectl
+	Name() string
+	// VersionsAPI returns the url listing the releases, the page number is appended
+	VersionsAPI() string
+	// VersionSource returns where the published versions are listed
+	VersionSource() (versions.VersionSource, error)
+	// DownloadURL returns the url of the artifact of version for the platform
+	DownloadURL(version string, platform *helpers.OSArch) string
+	// ChecksumURL returns the url of the file with the sha256 of the artifact
+	ChecksumURL(version string, platform *helpers.OSArch) string
+	// BinaryPath returns the path of the binary inside the artifact, or an
+	// empty string when the artifact is the binary itself
+	BinaryPath(platform *helpers.OSArch) string
+	// AssetName returns what the names of the GitHub release assets with the
+	// binary start with, or an empty string when the releases don't have them
+	AssetName() string
+}
+
+// Factory builds a provider from its urls, which may be the defaults or the
+// ones of a mirror.
+type Factory func(urls config.Tool) Provider
+
+type registration struct {
+	defaults config.Tool
+	factory  Factory
+}
+
+var registry = map[string]registration{}
+
+type Error struct {
+	Err  string
+	Name string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s\nprovider: %s", e.Err, e.Name)
+}
+
+// Register makes a provider available by name with its default urls.
+func Register(name string, defaults config.Tool, factory Factory) {
+	registry[name] = registration{defaults, factory}
+}
+
+// versionTagPattern matches the tags of the releases of kubectl and helm, like
+// v1.31.0 or v3.16.0-rc.1, skipping any other tag. The v is optional, so mirrors
+// that list plain versions work too.
+const versionTagPattern = `^v?(\d+\.\d+\.\d+(?:[-+].+)?)$`
+
+// Defaults returns the default urls of the provider.
+func Defaults(name string) (config.Tool, error) {
+	r, ok := registry[name]
+	if !ok {
+		return config.Tool{}, &Error{"provider not found", name}
+	}
+
+	return r.defaults, nil
+}
+
+// New returns the provider registered by name using the given urls. Empty
+// urls are taken from the defaults.
+func New(name string, urls config.Tool) (Provider, error) {
+	r, ok := registry[name]
+	if !ok {
+		return nil, &Error{"provider not found", name}
+	}
+
+	if urls.BinaryDownloadURL == "" {
+		urls.BinaryDownloadURL = r.defaults.BinaryDownloadURL
+	}
+
+	if urls.VersionsAPI == "" {
+		urls.VersionsAPI = r.defaults.VersionsAPI
+	}
+
+	if urls.VersionsSource == "" {
+		urls.VersionsSource = r.defaults.VersionsSource
+	}
+
+	if urls.TagPrefix == "" {
+		urls.TagPrefix = r.defaults.TagPrefix
+	}
+
+	if urls.TagPattern == "" {
+		urls.TagPattern = r.defaults.TagPattern
+	}
+
+	return r.factory(urls), nil
+}
+
+// Names returns the names of the registered providers.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// base implements the parts every provider built from url templates share.
+type base struct {
+	name string
+	urls config.Tool
+}
+
+func (b *base) Name() string {
+	return b.name
+}
+
+func (b *base) VersionsAPI() string {
+	return b.urls.VersionsAPI
+}
+
+func (b *base) VersionSource() (versions.VersionSource, error) {
+	return NewSource(b.urls)
+}
+
+// NewSource returns the source of the releases urls point to, taking the
+// versions from the tags as configured.
+func NewSource(urls config.Tool) (versions.VersionSource, error) {
+	tags, err := versions.NewTags(urls.TagPrefix, urls.TagPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions.NewSource(urls.VersionsSource, urls.VersionsAPI, tags)
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/config"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviders(t *testing.T) { // nolint: funlen
+	var (
+		linux   = &helpers.OSArch{OS: "linux", Arch: "amd64"}
+		darwin  = &helpers.OSArch{OS: "darwin", Arch: "arm64"}
+		windows = &helpers.OSArch{OS: "windows", Arch: "amd64"}
+	)
+
+	var flagtests = []struct {
+		testName    string
+		provider    string
+		version     string
+		platform    *helpers.OSArch
+		downloadURL string
+		checksumURL string
+		binaryPath  string
+	}{
+		{
+			"kubectl linux", "kubectl", "1.31.0", linux,
+			"https://dl.k8s.io/release/v1.31.0/bin/linux/amd64/kubectl",
+			"https://dl.k8s.io/release/v1.31.0/bin/linux/amd64/kubectl.sha256",
+			"",
+		},
+		{
+			"kubectl windows", "kubectl", "1.31.0", windows,
+			"https://dl.k8s.io/release/v1.31.0/bin/windows/amd64/kubectl.exe",
+			"https://dl.k8s.io/release/v1.31.0/bin/windows/amd64/kubectl.exe.sha256",
+			"",
+		},
+		{
+			"helm darwin", "helm", "3.19.0", darwin,
+			"https://get.helm.sh/helm-v3.19.0-darwin-arm64.tar.gz",
+			"https://get.helm.sh/helm-v3.19.0-darwin-arm64.tar.gz.sha256sum",
+			"darwin-arm64/helm",
+		},
+		{
+			"helm windows", "helm", "3.19.0", windows,
+			"https://get.helm.sh/helm-v3.19.0-windows-amd64.zip",
+			"https://get.helm.sh/helm-v3.19.0-windows-amd64.zip.sha256sum",
+			"windows-amd64/helm.exe",
+		},
+		{
+			"oc darwin", "oc", "4.15.0-0.okd-2024-03-10-010116", darwin,
+			"https://github.com/openshift/okd/releases/download/4.15.0-0.okd-2024-03-10-010116/openshift-client-mac-4.15.0-0.okd-2024-03-10-010116.tar.gz", // nolint: lll
+			"https://github.com/openshift/okd/releases/download/4.15.0-0.okd-2024-03-10-010116/sha256sum.txt",
+			"oc",
+		},
+		{
+			"oc windows", "oc", "4.15.0-0.okd-2024-03-10-010116", windows,
+			"https://github.com/openshift/okd/releases/download/4.15.0-0.okd-2024-03-10-010116/openshift-client-windows-4.15.0-0.okd-2024-03-10-010116.zip", // nolint: lll
+			"https://github.com/openshift/okd/releases/download/4.15.0-0.okd-2024-03-10-010116/sha256sum.txt",
+			"oc.exe",
+		},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			p, err := New(tt.provider, config.Tool{})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.provider, p.Name())
+			assert.Equal(t, tt.downloadURL, p.DownloadURL(tt.version, tt.platform))
+			assert.Equal(t, tt.checksumURL, p.ChecksumURL(tt.version, tt.platform))
+			assert.Equal(t, tt.binaryPath, p.BinaryPath(tt.platform))
+		})
+	}
+}
+
+func TestMirror(t *testing.T) {
+	p, err := New("helm", config.Tool{BinaryDownloadURL: "https://mirror.corp/helm/helm-v%s-%s-%s"})
+	require.NoError(t, err)
+
+	linux := &helpers.OSArch{OS: "linux", Arch: "amd64"}
+
+	assert.Equal(t, "https://mirror.corp/helm/helm-v3.19.0-linux-amd64.tar.gz", p.DownloadURL("3.19.0", linux))
+	assert.Equal(t, "https://api.github.com/repos/helm/helm/releases?per_page=100&page=", p.VersionsAPI())
+
+	_, err = New("unknown", config.Tool{})
+	assert.IsType(t, &Error{}, err)
+}
+
+func TestKubectlVersionSource(t *testing.T) {
+	p, err := New("kubectl", config.Tool{BinaryDownloadURL: "https://mirror.corp/dl-k8s/release/v%s/bin/%s/%s/kubectl"})
+	require.NoError(t, err)
+
+	source, err := p.VersionSource()
+	require.NoError(t, err)
+
+	fallback, ok := source.(versions.Fallback)
+	require.True(t, ok)
+	assert.Equal(t, &versions.Markers{Endpoint: "https://mirror.corp/dl-k8s/release/"}, fallback[0])
+	assert.IsType(t, &versions.GitHub{}, fallback[1])
+
+	// A configured source is used as is
+	p, err = New("kubectl", config.Tool{VersionsAPI: "https://mirror.corp/versions.txt", VersionsSource: "index"})
+	require.NoError(t, err)
+
+	source, err = p.VersionSource()
+	require.NoError(t, err)
+
+	index, ok := source.(*versions.Index)
+	require.True(t, ok)
+	assert.Equal(t, "https://mirror.corp/versions.txt", index.Endpoint)
+	assert.Equal(t, versionTagPattern, index.Tags.Pattern.String())
+}
+
+func TestDefaultTags(t *testing.T) {
+	var flagtests = []struct {
+		provider string
+		tag      string
+		expected string
+	}{
+		{"kubectl", "v1.31.0", "1.31.0"},
+		{"kubectl", "v1.31.0-rc.1", "1.31.0-rc.1"},
+		{"kubectl", "1.31.0", "1.31.0"},
+		{"kubectl", "v1.31", ""},
+		{"kubectl", "staging/v0.31.0-beta", ""},
+		{"helm", "v3.16.0", "3.16.0"},
+		{"helm", "v3.16.0+g6a1fd3c", "3.16.0+g6a1fd3c"},
+		{"helm", "v3.16.0.1", ""},
+		{"helm", "chart-1.0.0", ""},
+		{"oc", "4.15.0-0.okd-2024-03-10-010116", "4.15.0-0.okd-2024-03-10-010116"},
+		{"oc", "4.16.0-0.okd-scos-2024-07-20-041305", "4.16.0-0.okd-scos-2024-07-20-041305"},
+		{"oc", "4.16.0-okd-scos.ec.1", "4.16.0-okd-scos.ec.1"},
+		{"oc", "4.15.0", ""},
+		{"oc", "v4.15.0-0.okd-2024-03-10-010116", ""},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.provider+" "+tt.tag, func(t *testing.T) {
+			defaults, err := Defaults(tt.provider)
+			require.NoError(t, err)
+
+			tags, err := versions.NewTags(defaults.TagPrefix, defaults.TagPattern)
+			require.NoError(t, err)
+
+			v, ok := tags.Parse(tt.tag)
+			if tt.expected == "" {
+				assert.False(t, ok)
+				return
+			}
+
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, v.String())
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package versions
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+)
+
+// Asset is a file attached to a GitHub release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// The names used for every os and arch in the asset names. Assets without an
+// arch in their name are taken as amd64, and universal ones as any arch.
+var (
+	platformOS   = []string{"linux", "darwin", "windows"}
+	platformArch = []string{"amd64", "arm64", "arm"}
+	osAliases    = map[string][]string{
+		"linux":   {"linux"},
+		"darwin":  {"darwin", "mac", "macos", "osx", "apple"},
+		"windows": {"windows", "win", "win64"},
+	}
+	archAliases = map[string][]string{
+		"amd64": {"amd64", "x64", "64bit"},
+		"arm64": {"arm64", "aarch64"},
+		"arm":   {"arm", "armv6", "armv7", "armhf"},
+	}
+	universalArch = "universal"
+	defaultArch   = "amd64"
+)
+
+// ignoredExts are the extensions of release assets that aren't binaries, like
+// signatures or packages for other installers.
+var ignoredExts = []string{
+	".asc", ".sig", ".pem", ".cert", ".crt", ".sbom", ".spdx", ".sha256", ".sha256sum",
+	".sha512", ".md5", ".txt", ".json", ".jsonl", ".deb", ".rpm", ".apk", ".msi", ".pkg", ".dmg",
+}
+
+// MatchAsset returns the asset with the binary for the platform, among the ones
+// whose name starts with prefix. When several match, the ones naming the arch
+// explicitly are preferred, then the shortest name.
+func MatchAsset(assets []Asset, platform *helpers.OSArch, prefix string) (*Asset, bool) {
+	var (
+		best      *Asset
+		bestScore int
+	)
+
+	for i := range assets {
+		score := assetScore(assets[i].Name, platform, prefix)
+		if score == 0 {
+			continue
+		}
+
+		if best == nil || score > bestScore ||
+			(score == bestScore && len(assets[i].Name) < len(best.Name)) ||
+			(score == bestScore && len(assets[i].Name) == len(best.Name) && assets[i].Name < best.Name) {
+			best, bestScore = &assets[i], score
+		}
+	}
+
+	return best, best != nil
+}
+
+// Platforms returns the os/arch pairs the assets have a binary for.
+func Platforms(assets []Asset, prefix string) []string {
+	var platforms []string
+
+	for _, goos := range platformOS {
+		for _, arch := range platformArch {
+			platform := &helpers.OSArch{OS: goos, Arch: arch}
+
+			if _, ok := MatchAsset(assets, platform, prefix); ok {
+				platforms = append(platforms, platform.String())
+			}
+		}
+	}
+
+	sort.Strings(platforms)
+
+	return platforms
+}
+
+// assetScore is 0 when the asset isn't for the platform, 1 when it's assumed
+// to be for its arch and 2 when it names it.
+func assetScore(name string, platform *helpers.OSArch, prefix string) int {
+	lower := strings.ToLower(name)
+
+	if !strings.HasPrefix(lower, strings.ToLower(prefix)) {
+		return 0
+	}
+
+	for _, ext := range ignoredExts {
+		if path.Ext(lower) == ext {
+			return 0
+		}
+	}
+
+	tokens := assetTokens(lower)
+
+	for _, goos := range platformOS {
+		if hasAny(tokens, osAliases[goos]) != (goos == platform.OS) {
+			return 0
+		}
+	}
+
+	if hasAny(tokens, archAliases[platform.Arch]) || (platform.IsDarwin() && tokens[universalArch]) {
+		return 2 // nolint: mnd
+	}
+
+	for _, arch := range platformArch {
+		if hasAny(tokens, archAliases[arch]) {
+			return 0
+		}
+	}
+
+	if platform.Arch == defaultArch {
+		return 1
+	}
+
+	return 0
+}
+
+// assetTokens splits an asset name in its words, like
+// k9s_Linux_amd64.tar.gz in k9s, linux, amd64, tar and gz.
+func assetTokens(name string) map[string]bool {
+	tokens := map[string]bool{}
+
+	name = strings.NewReplacer("x86_64", "amd64", "x86-64", "amd64").Replace(name)
+
+	for _, token := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		tokens[token] = true
+	}
+
+	return tokens
+}
+
+func hasAny(tokens map[string]bool, words []string) bool {
+	for _, word := range words {
+		if tokens[word] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// releaseURL returns the url of the release of tag in the GitHub API, given
+// the versions endpoint of the repository.
+func releaseURL(endpoint string, tag string) (string, error) {
+	base, _, _ := strings.Cut(endpoint, "?")
+
+	if !strings.HasSuffix(base, "/releases") {
+		return "", fmt.Errorf("not a GitHub releases endpoint: %s", endpoint)
+	}
+
+	return base + "/tags/" + url.PathEscape(tag), nil
+}
@@ -0,0 +1,118 @@
+package versions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assetList(names ...string) []Asset {
+	assets := make([]Asset, 0, len(names))
+
+	for _, name := range names {
+		assets = append(assets, Asset{Name: name, URL: "https://example.com/" + name})
+	}
+
+	return assets
+}
+
+func TestMatchAsset(t *testing.T) { // nolint: funlen
+	var (
+		okd = assetList(
+			"openshift-client-linux-4.16.0-0.okd-2024-07-13.tar.gz",
+			"openshift-client-linux-amd64-rhel8-4.16.0-0.okd-2024-07-13.tar.gz",
+			"openshift-client-linux-amd64-rhel9-4.16.0-0.okd-2024-07-13.tar.gz",
+			"openshift-client-linux-arm64-4.16.0-0.okd-2024-07-13.tar.gz",
+			"openshift-client-mac-4.16.0-0.okd-2024-07-13.tar.gz",
+			"openshift-client-mac-arm64-4.16.0-0.okd-2024-07-13.tar.gz",
+			"openshift-client-windows-4.16.0-0.okd-2024-07-13.zip",
+			"openshift-install-linux-4.16.0-0.okd-2024-07-13.tar.gz",
+			"sha256sum.txt",
+		)
+		k9s = assetList(
+			"checksums.sha256",
+			"k9s_Darwin_amd64.tar.gz",
+			"k9s_Darwin_arm64.tar.gz",
+			"k9s_Linux_amd64.tar.gz",
+			"k9s_Linux_amd64.tar.gz.sbom",
+			"k9s_Linux_arm64.tar.gz",
+			"k9s_linux_amd64.deb",
+			"k9s_Windows_amd64.zip",
+		)
+	)
+
+	var flagtests = []struct {
+		testName string
+		assets   []Asset
+		prefix   string
+		platform *helpers.OSArch
+		expected string
+	}{
+		{"okd linux amd64", okd, "openshift-client", &helpers.OSArch{OS: "linux", Arch: "amd64"},
+			"openshift-client-linux-amd64-rhel8-4.16.0-0.okd-2024-07-13.tar.gz"},
+		{"okd linux arm64", okd, "openshift-client", &helpers.OSArch{OS: "linux", Arch: "arm64"},
+			"openshift-client-linux-arm64-4.16.0-0.okd-2024-07-13.tar.gz"},
+		{"okd darwin amd64", okd, "openshift-client", &helpers.OSArch{OS: "darwin", Arch: "amd64"},
+			"openshift-client-mac-4.16.0-0.okd-2024-07-13.tar.gz"},
+		{"okd darwin arm64", okd, "openshift-client", &helpers.OSArch{OS: "darwin", Arch: "arm64"},
+			"openshift-client-mac-arm64-4.16.0-0.okd-2024-07-13.tar.gz"},
+		{"okd windows", okd, "openshift-client", &helpers.OSArch{OS: "windows", Arch: "amd64"},
+			"openshift-client-windows-4.16.0-0.okd-2024-07-13.zip"},
+		{"okd linux arm", okd, "openshift-client", &helpers.OSArch{OS: "linux", Arch: "arm"}, ""},
+		{"k9s linux", k9s, "k9s", &helpers.OSArch{OS: "linux", Arch: "amd64"}, "k9s_Linux_amd64.tar.gz"},
+		{"k9s darwin", k9s, "k9s", &helpers.OSArch{OS: "darwin", Arch: "arm64"}, "k9s_Darwin_arm64.tar.gz"},
+		{"x86_64", assetList("tool_linux_x86_64.tar.gz"), "tool", &helpers.OSArch{OS: "linux", Arch: "amd64"},
+			"tool_linux_x86_64.tar.gz"},
+		{"universal", assetList("tool-darwin-universal"), "tool", &helpers.OSArch{OS: "darwin", Arch: "arm64"},
+			"tool-darwin-universal"},
+		{"only signatures", assetList("helm-v3.15.0-linux-amd64.tar.gz.asc"), "helm",
+			&helpers.OSArch{OS: "linux", Arch: "amd64"}, ""},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			asset, ok := MatchAsset(tt.assets, tt.platform, tt.prefix)
+
+			if tt.expected == "" {
+				assert.False(t, ok)
+			} else {
+				require.True(t, ok)
+				assert.Equal(t, tt.expected, asset.Name)
+			}
+		})
+	}
+
+	assert.Equal(t, []string{"darwin/amd64", "darwin/arm64", "linux/amd64", "linux/arm64", "windows/amd64"},
+		Platforms(okd, "openshift-client"))
+}
+
+func TestGetRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/repos/openshift/okd/releases/tags/4.16.0-0.okd-2024-07-13" {
+			http.NotFound(rw, req)
+			return
+		}
+
+		fmt.Fprint(rw, `{"tag_name": "4.16.0-0.okd-2024-07-13", "assets": [
+			{"name": "sha256sum.txt", "browser_download_url": "https://example.com/sha256sum.txt", "size": 1024}
+		]}`)
+	}))
+	defer server.Close()
+
+	endpoint := server.URL + "/repos/openshift/okd/releases?per_page=100&page="
+
+	release, err := GetRelease(endpoint, "4.16.0-0.okd-2024-07-13")
+	require.NoError(t, err)
+	assert.Equal(t, []Asset{{"sha256sum.txt", "https://example.com/sha256sum.txt", 1024}}, release.Assets)
+
+	_, err = GetRelease(endpoint, "4.17.0")
+	require.Error(t, err)
+
+	_, err = GetRelease(server.URL+"/versions.txt", "4.16.0")
+	require.Error(t, err)
+}
@@ -0,0 +1,235 @@
+package versions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/cache"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+)
+
+var (
+	// Refresh makes the lists of versions be fetched again, whether cached or
+	// not, without conditional requests.
+	Refresh bool
+	// Offline makes the lists of versions be read only from the cache.
+	Offline bool
+)
+
+// Cached keeps the releases of Source in the cache for TTL. Once it expires
+// they're fetched again, with conditional requests, so the pages that didn't
+// change aren't downloaded again nor count against the rate limits. With a TTL
+// of 0 they're always fetched, and the cached ones only used offline.
+type Cached struct {
+	Source VersionSource
+	// Tool is the name the releases are kept under
+	Tool string
+	// Origin tells where the releases come from, they're fetched again if the
+	// cached ones came from somewhere else
+	Origin string
+	TTL    time.Duration
+	// Optional tells the releases are only nice to have, so they're fetched
+	// without retries nor waiting for the rate limits to reset
+	Optional bool
+}
+
+type cachedRelease struct {
+	Version    string    `json:"version"`
+	Tag        string    `json:"tag,omitempty"`
+	Prerelease bool      `json:"prerelease,omitempty"`
+	Draft      bool      `json:"draft,omitempty"`
+	Published  time.Time `json:"published"`
+}
+
+type cachedReleases struct {
+	Origin   string          `json:"origin"`
+	Fetched  time.Time       `json:"fetched"`
+	Releases []cachedRelease `json:"releases"`
+}
+
+func (s *Cached) Versions() ([]*version.Version, error) {
+	releases, err := s.Releases()
+	if err != nil {
+		return nil, err
+	}
+
+	return ReleaseVersions(releases), nil
+}
+
+func (s *Cached) Releases() ([]*Release, error) {
+	cached, fetched, err := s.load()
+
+	switch {
+	case Offline && err != nil:
+		return nil, fmt.Errorf("no versions of %s cached, they can't be listed offline: %w", s.Tool, err)
+	case Offline:
+		logging.Debug("using cached versions offline", "tool", s.Tool, "fetched", fetched)
+		return cached, nil
+	case !Refresh && err == nil && time.Since(fetched) < s.TTL:
+		logging.Debug("using cached versions", "tool", s.Tool, "fetched", fetched)
+		return cached, nil
+	}
+
+	if s.Optional {
+		defer withoutRetries()()
+	}
+
+	releases, err := GetReleases(s.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store(releases); err != nil {
+		logging.Warn("could not cache the versions", "tool", s.Tool, "error", err)
+	}
+
+	return releases, nil
+}
+
+// Cached returns the releases in the cache, however old they are, without
+// fetching them if there are none.
+func (s *Cached) Cached() ([]*Release, bool) {
+	releases, _, err := s.load()
+
+	return releases, err == nil
+}
+
+// Channel looks for the channel in the cached versions while they're fresh,
+// and asks the source for it once they've expired. Offline it's looked for in
+// the cached versions, however old they are.
+func (s *Cached) Channel(name string) (*version.Version, error) {
+	cached, fetched, err := s.load()
+
+	switch {
+	case Offline && err != nil:
+		return nil, fmt.Errorf("no versions of %s cached, the %s channel can't be read offline: %w", s.Tool, name, err)
+	case Offline:
+		return ChannelFrom(name, ReleaseVersions(cached))
+	case !Refresh && err == nil && time.Since(fetched) < s.TTL:
+		if v, err := ChannelFrom(name, ReleaseVersions(cached)); err == nil {
+			logging.Debug("using cached versions for the channel", "tool", s.Tool, "channel", name, "fetched", fetched)
+			return v, nil
+		}
+	}
+
+	cs, ok := s.Source.(ChannelSource)
+	if !ok {
+		return nil, fmt.Errorf("the %s channel can't be read", name)
+	}
+
+	return cs.Channel(name)
+}
+
+func (s *Cached) load() ([]*Release, time.Time, error) {
+	var cached cachedReleases
+
+	if !readJSON(cache.VersionsPath(s.Tool), &cached) {
+		return nil, time.Time{}, errors.New("nothing cached")
+	}
+
+	if cached.Origin != s.Origin {
+		return nil, time.Time{}, errors.New("the cached versions are from another source")
+	}
+
+	releases := make([]*Release, 0, len(cached.Releases))
+
+	for _, r := range cached.Releases {
+		v, err := version.NewVersion(r.Version)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		// Versions cached before the tags were aren't prefixed
+		tag := r.Tag
+		if tag == "" {
+			tag = r.Version
+		}
+
+		releases = append(releases, &Release{
+			Version:    v,
+			Tag:        tag,
+			Prerelease: r.Prerelease,
+			Draft:      r.Draft,
+			Published:  r.Published,
+		})
+	}
+
+	return releases, cached.Fetched, nil
+}
+
+func (s *Cached) store(releases []*Release) error {
+	cached := cachedReleases{
+		Origin:   s.Origin,
+		Fetched:  time.Now().UTC(),
+		Releases: make([]cachedRelease, 0, len(releases)),
+	}
+
+	for _, r := range releases {
+		cached.Releases = append(cached.Releases, cachedRelease{
+			Version:    r.Version.Original(),
+			Tag:        r.Tag,
+			Prerelease: r.Prerelease,
+			Draft:      r.Draft,
+			Published:  r.Published,
+		})
+	}
+
+	return writeJSON(cache.VersionsPath(s.Tool), cached)
+}
+
+// writeJSON writes v to path, through a temporary file so it's never read half
+// written.
+func writeJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil { // nolint: mnd
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// readJSON reads path into v, telling if there was something to read.
+func readJSON(path string, v any) bool {
+	data, err := os.ReadFile(path) // nolint: gosec
+	if errors.Is(err, fs.ErrNotExist) {
+		return false
+	} else if err != nil {
+		logging.Debug("could not read cached file", "path", path, "error", err)
+		return false
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		logging.Debug("ignoring corrupt cached file", "path", path, "error", err)
+		return false
+	}
+
+	return true
+}
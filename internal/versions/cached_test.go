@@ -0,0 +1,182 @@
+package versions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// etagServer serves a page of releases with an ETag, counting the requests and
+// the ones answered with 304 Not Modified.
+func etagServer(t *testing.T, requests *int32, notModified *int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		if r.URL.Query().Get("page") != "1" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`[{"tag_name": "v3.15.0-rc.1", "prerelease": true}, {"tag_name": "v3.14.4"}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCachedTTL(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var requests, notModified int32
+
+	server := etagServer(t, &requests, &notModified)
+	source := &Cached{Source: &GitHub{Endpoint: server.URL + "/?page="}, Tool: "helm", Origin: server.URL, TTL: time.Hour}
+
+	releases, err := source.Releases()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3.15.0-rc.1", "3.14.4"}, releaseStrings(releases))
+	assert.True(t, releases[0].Prerelease)
+
+	fetched := atomic.LoadInt32(&requests)
+
+	// Cached for an hour, and the flags are kept
+	releases, err = source.Releases()
+	require.NoError(t, err)
+	assert.Equal(t, fetched, atomic.LoadInt32(&requests))
+	assert.True(t, releases[0].Prerelease)
+	assert.Equal(t, "v3.15.0-rc.1", releases[0].Tag)
+
+	// Another source isn't taken from the cache
+	other := &Cached{Source: source.Source, Tool: "helm", Origin: "elsewhere", TTL: time.Hour}
+	_, err = other.Releases()
+	require.NoError(t, err)
+	assert.Greater(t, atomic.LoadInt32(&requests), fetched)
+}
+
+func TestCachedETag(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var requests, notModified int32
+
+	server := etagServer(t, &requests, &notModified)
+	source := &Cached{Source: &GitHub{Endpoint: server.URL + "/?page="}, Tool: "helm", Origin: server.URL}
+
+	_, err := source.Releases()
+	require.NoError(t, err)
+	assert.Zero(t, atomic.LoadInt32(&notModified))
+
+	// Expired right away, so asked again, but the page didn't change
+	releases, err := source.Releases()
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified))
+	assert.Equal(t, []string{"3.15.0-rc.1", "3.14.4"}, releaseStrings(releases))
+
+	Refresh = true
+	defer func() { Refresh = false }()
+
+	_, err = source.Releases()
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified), "a refresh doesn't send conditional requests")
+}
+
+func TestCachedOffline(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var requests, notModified int32
+
+	server := etagServer(t, &requests, &notModified)
+	source := &Cached{Source: &GitHub{Endpoint: server.URL + "/?page="}, Tool: "helm", Origin: server.URL}
+
+	Offline = true
+	defer func() { Offline = false }()
+
+	_, err := source.Releases()
+	assert.Error(t, err, "nothing is cached yet")
+
+	Offline = false
+
+	_, err = source.Releases()
+	require.NoError(t, err)
+
+	Offline = true
+	fetched := atomic.LoadInt32(&requests)
+
+	versions, err := source.Versions()
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+
+	stable, err := source.Channel("stable-3.14")
+	require.NoError(t, err)
+	assert.Equal(t, "v3.14.4", stable.Original())
+
+	latest, err := ResolveChannel(source, "latest-3.15")
+	require.NoError(t, err)
+	assert.Equal(t, "v3.15.0-rc.1", latest.Original())
+	assert.Equal(t, fetched, atomic.LoadInt32(&requests))
+}
+
+func TestCachedChannel(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var requests int32
+
+	markers := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		if req.URL.Path != "/release/stable-1.29.txt" {
+			http.NotFound(rw, req)
+			return
+		}
+
+		_, _ = rw.Write([]byte("v1.29.7\n"))
+	}))
+	defer markers.Close()
+
+	index := markersServer(t, map[string]string{"/versions.txt": "1.29.6\n1.30.2"})
+	defer index.Close()
+
+	source := &Cached{
+		Source: Fallback{&Markers{Endpoint: markers.URL + "/release/"}, &Index{Endpoint: index.URL + "/versions.txt"}},
+		Tool:   "kubectl",
+		Origin: markers.URL,
+		TTL:    time.Hour,
+	}
+
+	// Nothing cached, so the channel is read from upstream
+	actual, err := source.Channel("stable-1.29")
+	require.NoError(t, err)
+	assert.Equal(t, "1.29.7", actual.String())
+
+	_, err = source.Versions()
+	require.NoError(t, err)
+	atomic.StoreInt32(&requests, 0)
+
+	// The fresh versions are enough
+	actual, err = source.Channel("stable-1.29")
+	require.NoError(t, err)
+	assert.Equal(t, "1.29.6", actual.String())
+	assert.Zero(t, atomic.LoadInt32(&requests))
+
+	// Once they've expired, it's read again
+	source.TTL = 0
+
+	actual, err = source.Channel("stable-1.29")
+	require.NoError(t, err)
+	assert.Equal(t, "1.29.7", actual.String())
+	assert.Positive(t, atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,139 @@
+package versions
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/go-version"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+)
+
+// Channels are names for the newest version of a release line, like the
+// markers of Kubernetes: stable, stable-1.29 or latest-1.30. The stable ones
+// skip prereleases, the latest ones don't.
+var channelRegexp = regexp.MustCompile(`^(stable|latest)(?:-(\d+)(?:\.(\d+))?)?$`)
+
+// ChannelSource is implemented by the sources that publish their channels,
+// so they don't need to be computed from the list of versions.
+type ChannelSource interface {
+	Channel(name string) (*version.Version, error)
+}
+
+type channel struct {
+	stable bool
+	// segments are the major and minor of the release line, if any
+	segments []int
+}
+
+// IsChannel tells if name is a channel. latest alone isn't, it's the newest
+// stable version as a constraint.
+func IsChannel(name string) bool {
+	_, ok := parseChannel(name)
+
+	return ok
+}
+
+func parseChannel(name string) (*channel, bool) {
+	match := channelRegexp.FindStringSubmatch(name)
+	if match == nil || name == Latest {
+		return nil, false
+	}
+
+	c := &channel{stable: match[1] == "stable"}
+
+	for _, segment := range match[2:] {
+		if segment != "" {
+			n, _ := strconv.Atoi(segment)
+			c.segments = append(c.segments, n)
+		}
+	}
+
+	return c, true
+}
+
+// IsStable tells if v isn't a prerelease, like an alpha or 1.30.0-dev. The
+// builds of OKD, which look like prereleases, are stable.
+func IsStable(v *version.Version) bool {
+	return (&Release{Version: v}).Stability() == StabilityStable
+}
+
+// ResolveChannel returns the version the channel points to, asking the source
+// if it publishes channels and looking for it in its versions otherwise.
+func ResolveChannel(source VersionSource, name string) (*version.Version, error) {
+	if !IsChannel(name) {
+		return nil, fmt.Errorf("invalid channel %q", name)
+	}
+
+	if cs, ok := source.(ChannelSource); ok {
+		v, err := cs.Channel(name)
+		if err == nil {
+			return v, nil
+		}
+
+		logging.Debug("could not read the channel, looking for it in the versions", "channel", name, "error", err)
+	}
+
+	versions, err := source.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	return ChannelFrom(name, versions)
+}
+
+// ChannelFrom returns the newest of versions in the channel.
+func ChannelFrom(name string, versions []*version.Version) (*version.Version, error) {
+	var best *version.Version
+
+	c, ok := parseChannel(name)
+	if !ok {
+		return nil, fmt.Errorf("invalid channel %q", name)
+	}
+
+	for _, v := range versions {
+		if c.stable && !IsStable(v) {
+			continue
+		}
+
+		if !c.matches(v) {
+			continue
+		}
+
+		if best == nil || Newer(v, best) {
+			best = v
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no version in the %s channel", name)
+	}
+
+	return best, nil
+}
+
+func (c *channel) matches(v *version.Version) bool {
+	for i, segment := range c.segments {
+		if v.Segments()[i] != segment {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Channel reads the marker of the channel, dl.k8s.io has one for each.
+func (s *Markers) Channel(name string) (*version.Version, error) {
+	return s.marker(newClient(""), name)
+}
+
+// Channel asks the first of the sources that publishes channels.
+func (s Fallback) Channel(name string) (*version.Version, error) {
+	for _, source := range s {
+		if cs, ok := source.(ChannelSource); ok {
+			return cs.Channel(name)
+		}
+	}
+
+	return nil, fmt.Errorf("no source publishes the %s channel", name)
+}
@@ -0,0 +1,89 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsStable(t *testing.T) {
+	for v, expected := range map[string]bool{
+		"1.30.2":                              true,
+		"1.30.3+corp.1":                       true,
+		"4.15.0-0.okd-2024-03-10-010116":      true,
+		"4.16.0-0.okd-scos-2024-07-20-041305": true,
+		"4.17.0-okd-scos.ec.1":                false,
+		"1.31.0-rc.1":                         false,
+		"1.31.0-beta.0":                       false,
+		"3.0.0-alpha1":                        false,
+		"3.16.0-dev":                          false,
+		"1.0.0-preview.1":                     false,
+		"2.0.0-pre":                           false,
+		"1.2.3-snapshot":                      false,
+	} {
+		assert.Equal(t, expected, IsStable(version.Must(version.NewVersion(v))), v)
+	}
+}
+
+func TestChannelFrom(t *testing.T) {
+	var candidates []*version.Version
+
+	for _, v := range []string{
+		"1.29.6", "1.30.1", "1.30.2", "1.30.3-rc.0", "1.30.4-dev", "1.31.0-beta.1", "2.0.0-alpha.1",
+	} {
+		candidates = append(candidates, version.Must(version.NewVersion(v)))
+	}
+
+	var flagtests = []struct {
+		channel  string
+		expected string
+	}{
+		{"stable", "1.30.2"},
+		{"stable-1", "1.30.2"},
+		{"stable-1.29", "1.29.6"},
+		{"latest-1.30", "1.30.4-dev"},
+		{"latest-1.31", "1.31.0-beta.1"},
+		{"latest-2", "2.0.0-alpha.1"},
+		{"stable-1.31", ""},
+		{"stable-1.28", ""},
+		{"latest", ""},
+		{"unstable", ""},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.channel, func(t *testing.T) {
+			actual, err := ChannelFrom(tt.channel, candidates)
+
+			if tt.expected == "" {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, actual.String())
+			}
+		})
+	}
+}
+
+func TestResolveChannel(t *testing.T) {
+	markers := markersServer(t, map[string]string{"/release/stable-1.29.txt": "v1.29.7"})
+	defer markers.Close()
+
+	index := markersServer(t, map[string]string{"/versions.txt": "1.29.6\n1.30.2"})
+	defer index.Close()
+
+	// Published channels are read, the versions are the fallback
+	source := Fallback{&Markers{Endpoint: markers.URL + "/release/"}, &Index{Endpoint: index.URL + "/versions.txt"}}
+
+	actual, err := ResolveChannel(source, "stable-1.29")
+	require.NoError(t, err)
+	assert.Equal(t, "1.29.7", actual.String())
+
+	actual, err = ResolveChannel(&Index{Endpoint: index.URL + "/versions.txt"}, "stable")
+	require.NoError(t, err)
+	assert.Equal(t, "1.30.2", actual.String())
+
+	_, err = ResolveChannel(source, "1.29")
+	require.Error(t, err)
+}
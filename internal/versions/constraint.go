@@ -0,0 +1,88 @@
+package versions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Latest is the version expression for the newest stable version.
+const Latest = "latest"
+
+// IsExact tells if expr is a complete version, like 1.29.3 or
+// 4.15.0-0.okd-2024-03-10-010116, and not something to resolve.
+func IsExact(expr string) bool {
+	if _, err := version.NewVersion(expr); err != nil {
+		return false
+	}
+
+	core := strings.TrimPrefix(strings.TrimSpace(expr), "v")
+	core, _, _ = strings.Cut(core, "+")
+	core, prerelease, _ := strings.Cut(core, "-")
+
+	return prerelease != "" || strings.Count(core, ".") >= 2 // nolint: mnd
+}
+
+// ParseConstraint returns the constraints expr stands for. A partial version
+// is its latest patch (1.29 is ~> 1.29.0), latest any stable version, and
+// anything else a go-version constraint, like ~> 1.29.0 or >= 1.28, < 1.30.
+func ParseConstraint(expr string) (version.Constraints, error) {
+	expr = strings.TrimSpace(expr)
+
+	if expr == Latest {
+		return version.NewConstraint(">= 0")
+	}
+
+	if v, err := version.NewVersion(expr); err == nil && !IsExact(expr) {
+		segments := strings.Count(strings.TrimPrefix(expr, "v"), ".") + 1
+		if segments == 1 {
+			return version.NewConstraint(fmt.Sprintf("~> %d.0", v.Segments()[0]))
+		}
+
+		return version.NewConstraint(fmt.Sprintf("~> %d.%d.0", v.Segments()[0], v.Segments()[1]))
+	}
+
+	return version.NewConstraint(expr)
+}
+
+// Resolve returns the newest of candidates matching expr, which can be an
+// exact version or a channel too. Prereleases only match constraints that name
+// one, but the builds of OKD match by their release, so 4.15 is the newest
+// build of 4.15.0.
+func Resolve(expr string, candidates []*version.Version) (*version.Version, error) {
+	var best *version.Version
+
+	if IsChannel(expr) {
+		return ChannelFrom(expr, candidates)
+	}
+
+	if IsExact(expr) {
+		want := version.Must(version.NewVersion(expr))
+
+		for _, candidate := range candidates {
+			if candidate.Equal(want) && candidate.Metadata() == want.Metadata() {
+				return candidate, nil
+			}
+		}
+
+		return nil, fmt.Errorf("version %s not found", expr)
+	}
+
+	constraints, err := ParseConstraint(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version or constraint %q: %w", expr, err)
+	}
+
+	for _, candidate := range candidates {
+		if matches(constraints, candidate) && (best == nil || Newer(candidate, best)) {
+			best = candidate
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no version matches %s", expr)
+	}
+
+	return best, nil
+}
@@ -0,0 +1,65 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsExact(t *testing.T) {
+	for expr, expected := range map[string]bool{
+		"1.29.3":                         true,
+		"v1.29.3":                        true,
+		"1.30.2+corp.1":                  true,
+		"1.31.0-rc.1":                    true,
+		"4.15.0-0.okd-2024-03-10-010116": true,
+		"1.29":                           false,
+		"1":                              false,
+		"~> 1.29.0":                      false,
+		">= 1.28, < 1.30":                false,
+		"latest":                         false,
+	} {
+		assert.Equal(t, expected, IsExact(expr), expr)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	var candidates []*version.Version
+
+	for _, v := range []string{"1.28.9", "1.29.0", "1.29.6", "1.30.2", "1.30.3+corp.1", "1.31.0-rc.1", "2.0.0-beta.1"} {
+		candidates = append(candidates, version.Must(version.NewVersion(v)))
+	}
+
+	var flagtests = []struct {
+		expr     string
+		expected string
+	}{
+		{"1.29", "1.29.6"},
+		{"v1.29", "1.29.6"},
+		{"1", "1.30.3+corp.1"},
+		{"~> 1.29.0", "1.29.6"},
+		{">= 1.28, < 1.30", "1.29.6"},
+		{"latest", "1.30.3+corp.1"},
+		{"1.30.2", "1.30.2"},
+		{"1.31.0-rc.1", "1.31.0-rc.1"},
+		{">= 1.31.0-rc.0", "1.31.0-rc.1"},
+		{"1.32", ""},
+		{"1.29.5", ""},
+		{"~>", ""},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.expr, func(t *testing.T) {
+			actual, err := Resolve(tt.expr, candidates)
+
+			if tt.expected == "" {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, actual.String())
+			}
+		})
+	}
+}
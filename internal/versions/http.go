@@ -1,14 +1,17 @@
 package versions
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/cache"
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
 )
 
@@ -72,3 +75,90 @@ func (art *AuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 	// Execute the request using the next RoundTripper in the chain.
 	return art.nextRoundTripper.RoundTrip(req)
 }
+
+// cachedResponse is the last response to a request, kept to ask if it changed.
+type cachedResponse struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// ETagRoundTripper keeps the responses with an ETag in the cache and asks if
+// they changed with If-None-Match when they're requested again. Unchanged ones
+// are not sent again and don't count against the rate limits of GitHub. Offline
+// the cached ones are used as they are.
+type ETagRoundTripper struct {
+	nextRoundTripper http.RoundTripper
+}
+
+func (ert *ETagRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var cached cachedResponse
+
+	if req.Method != http.MethodGet {
+		return ert.nextRoundTripper.RoundTrip(req)
+	}
+
+	path := cache.ResponsePath(req.URL.String())
+	found := !Refresh && readJSON(path, &cached) && cached.ETag != ""
+
+	if Offline {
+		if !found {
+			return nil, fmt.Errorf("%s is not cached, it can't be requested offline", req.URL)
+		}
+
+		return cached.response(req), nil
+	}
+
+	if found {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := ert.nextRoundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if found && resp.StatusCode == http.StatusNotModified {
+		logging.Debug("response not modified, using the cached one", "url", req.URL.String())
+
+		resp.Body.Close()
+
+		return cached.response(req), nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := writeJSON(path, cachedResponse{etag, resp.Header, body}); err != nil {
+		logging.Debug("could not cache the response", "url", req.URL.String(), "error", err)
+	}
+
+	return resp, nil
+}
+
+// response returns the cached response as the response to req.
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusOK, http.StatusText(http.StatusOK)),
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.Header,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
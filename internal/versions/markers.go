@@ -0,0 +1,164 @@
+package versions
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/hashicorp/go-version"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+)
+
+// markerWorkers is how many markers are fetched at the same time.
+const markerWorkers = 8
+
+// Markers lists the versions of Kubernetes from the version markers of its
+// release bucket, like https://dl.k8s.io/release/stable-1.30.txt, which need no
+// token nor have rate limits. stable-X.Y.txt has the last patch of a minor, so
+// every patch up to it is listed, and latest-X.Y.txt its last prerelease.
+type Markers struct {
+	// Endpoint is the url the markers are in, like https://dl.k8s.io/release/
+	Endpoint string
+}
+
+func (s *Markers) Versions() ([]*version.Version, error) {
+	client := newClient("")
+
+	stable, err := s.marker(client, "stable")
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.marker(client, "latest")
+	if err != nil {
+		latest = stable
+	}
+
+	var (
+		major     = stable.Segments()[0]
+		markers   []string
+		versions  []*version.Version
+		mutex     sync.Mutex
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, markerWorkers)
+	)
+
+	for minor := latest.Segments()[1]; minor >= 0; minor-- {
+		markers = append(markers, fmt.Sprintf("stable-%d.%d", major, minor))
+
+		// Older minors only have prereleases of patches already published
+		if minor >= stable.Segments()[1] {
+			markers = append(markers, fmt.Sprintf("latest-%d.%d", major, minor))
+		}
+	}
+
+	for _, name := range markers {
+		wg.Add(1)
+
+		go func(name string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			v, err := s.marker(client, name)
+			if err != nil {
+				logging.Debug("skipping version marker", "marker", name, "error", err)
+				return
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			versions = append(versions, expandMarker(name, v)...)
+		}(name)
+	}
+
+	wg.Wait()
+
+	versions = dedupe(versions)
+	sort.Sort(sort.Reverse(version.Collection(versions)))
+
+	return versions, nil
+}
+
+// expandMarker returns the versions a marker stands for, every patch of the
+// minor for the stable ones.
+func expandMarker(name string, v *version.Version) []*version.Version {
+	if !strings.HasPrefix(name, "stable-") || v.Prerelease() != "" {
+		return []*version.Version{v}
+	}
+
+	segments := v.Segments()
+	expanded := make([]*version.Version, 0, segments[2]+1)
+
+	for patch := segments[2]; patch >= 0; patch-- {
+		patchVersion := fmt.Sprintf("%d.%d.%d", segments[0], segments[1], patch)
+		expanded = append(expanded, version.Must(version.NewVersion(patchVersion)))
+	}
+
+	return expanded
+}
+
+// dedupe removes the versions listed twice, like a latest marker pointing to a
+// stable release.
+func dedupe(versions []*version.Version) []*version.Version {
+	seen := map[string]bool{}
+	result := make([]*version.Version, 0, len(versions))
+
+	for _, v := range versions {
+		if !seen[v.String()] {
+			seen[v.String()] = true
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+func (s *Markers) marker(client *retryablehttp.Client, name string) (*version.Version, error) {
+	url := strings.TrimSuffix(s.Endpoint, "/") + "/" + name + ".txt"
+
+	logging.Debug("fetching version marker", "url", url)
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request to %s failed with %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024)) // nolint: mnd
+	if err != nil {
+		return nil, err
+	}
+
+	return version.NewVersion(strings.TrimSpace(string(data)))
+}
+
+// Fallback lists the versions of the first of its sources that works.
+type Fallback []VersionSource
+
+func (s Fallback) Versions() ([]*version.Version, error) {
+	var err error
+
+	for _, source := range s {
+		var versions []*version.Version
+
+		versions, err = source.Versions()
+		if err == nil {
+			return versions, nil
+		}
+
+		logging.Debug("versions source failed, trying the next one", "source", fmt.Sprintf("%T", source), "error", err)
+	}
+
+	return nil, err
+}
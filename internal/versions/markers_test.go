@@ -0,0 +1,59 @@
+package versions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func markersServer(t *testing.T, markers map[string]string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		marker, ok := markers[req.URL.Path]
+		if !ok {
+			http.NotFound(rw, req)
+			return
+		}
+
+		fmt.Fprintln(rw, marker)
+	}))
+}
+
+func TestMarkers(t *testing.T) {
+	server := markersServer(t, map[string]string{
+		"/release/stable.txt":      "v1.30.2",
+		"/release/latest.txt":      "v1.31.0-rc.0",
+		"/release/latest-1.31.txt": "v1.31.0-rc.0",
+		"/release/stable-1.30.txt": "v1.30.2",
+		"/release/latest-1.30.txt": "v1.30.3-rc.0",
+		"/release/stable-1.29.txt": "v1.29.1",
+		"/release/stable-1.27.txt": "v1.27.0",
+	})
+	defer server.Close()
+
+	source := &Markers{Endpoint: server.URL + "/release/"}
+
+	assert.Equal(t, []string{
+		"v1.31.0-rc.0", "v1.30.3-rc.0", "1.30.2", "1.30.1", "1.30.0", "1.29.1", "1.29.0", "1.27.0",
+	}, versionStrings(t, source))
+}
+
+func TestMarkersFallback(t *testing.T) {
+	markers := markersServer(t, map[string]string{})
+	defer markers.Close()
+
+	index := markersServer(t, map[string]string{"/versions.txt": "1.30.2\n1.30.1"})
+	defer index.Close()
+
+	source := Fallback{&Markers{Endpoint: markers.URL + "/release/"}, &Index{Endpoint: index.URL + "/versions.txt"}}
+
+	assert.Equal(t, []string{"1.30.2", "1.30.1"}, versionStrings(t, source))
+
+	_, err := Fallback{&Markers{Endpoint: markers.URL + "/release/"}}.Versions()
+	require.Error(t, err)
+}
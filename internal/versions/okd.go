@@ -0,0 +1,114 @@
+package versions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+)
+
+// The flavors of OKD, built on Fedora CoreOS or on CentOS Stream CoreOS.
+const (
+	FlavorFCOS = "okd"
+	FlavorSCOS = "scos"
+)
+
+// okdBuildLayout is the layout of the build date of OKD versions.
+const okdBuildLayout = "2006-01-02-150405"
+
+// okdRegexp matches the prerelease of OKD versions, like 0.okd-2024-03-10-010116,
+// 0.okd-scos-2024-07-20-041305 or okd-scos.ec.1 for the early candidates.
+var okdRegexp = regexp.MustCompile(`^(?:0\.)?okd(-scos)?[-.](.+)$`)
+
+// OKD is a build of OKD, whose versions are semver prereleases of the release
+// they're built for: 4.15.0-0.okd-2024-03-10-010116 is a build of 4.15.0 on
+// Fedora CoreOS from the 10th of March.
+type OKD struct {
+	Version *version.Version
+	// Stream is the minor the build belongs to, like 4.15
+	Stream string
+	Flavor string
+	// Build is when it was built, zero for the early candidates
+	Build time.Time
+}
+
+// ParseOKD returns the OKD build v is, if it's one.
+func ParseOKD(v *version.Version) (*OKD, bool) {
+	match := okdRegexp.FindStringSubmatch(v.Prerelease())
+	if match == nil {
+		return nil, false
+	}
+
+	okd := &OKD{
+		Version: v,
+		Stream:  Stream(v),
+		Flavor:  FlavorFCOS,
+	}
+
+	if match[1] != "" {
+		okd.Flavor = FlavorSCOS
+	}
+
+	if build, err := time.Parse(okdBuildLayout, match[2]); err == nil {
+		okd.Build = build
+	}
+
+	return okd, true
+}
+
+// ParseFlavor returns the OKD flavor name stands for: okd or fcos for the
+// Fedora CoreOS builds, scos or okd-scos for the CentOS Stream CoreOS ones.
+func ParseFlavor(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case FlavorFCOS, "fcos":
+		return FlavorFCOS, nil
+	case FlavorSCOS, "okd-scos":
+		return FlavorSCOS, nil
+	default:
+		return "", fmt.Errorf("invalid flavor %q, must be okd or scos", name)
+	}
+}
+
+// FilterFlavor returns the OKD builds of the flavor, keeping their order.
+func FilterFlavor(releases []*Release, flavor string) ([]*Release, error) {
+	flavor, err := ParseFlavor(flavor)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*Release, 0, len(releases))
+
+	for _, release := range releases {
+		if okd, ok := ParseOKD(release.Version); ok && okd.Flavor == flavor {
+			filtered = append(filtered, release)
+		}
+	}
+
+	return filtered, nil
+}
+
+// Newer tells if a is newer than b. They're compared as semver, but builds of
+// OKD for the same release by their build date, as their prereleases don't
+// sort by it when the flavors are mixed.
+func Newer(a *version.Version, b *version.Version) bool {
+	okdA, okA := ParseOKD(a)
+	okdB, okB := ParseOKD(b)
+
+	if !okA || !okB || !a.Core().Equal(b.Core()) || okdA.Build.Equal(okdB.Build) {
+		return a.GreaterThan(b)
+	}
+
+	return okdA.Build.After(okdB.Build)
+}
+
+// matches tells if v meets the constraints. The builds of OKD are releases,
+// so they're checked by the release they're built for.
+func matches(constraints version.Constraints, v *version.Version) bool {
+	if _, ok := ParseOKD(v); ok && IsStable(v) {
+		return constraints.Check(v.Core())
+	}
+
+	return constraints.Check(v)
+}
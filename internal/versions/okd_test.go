@@ -0,0 +1,126 @@
+package versions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// okdVersions are builds of both flavors, interleaved like in the releases of
+// OKD.
+var okdVersions = []string{
+	"4.15.0-0.okd-2024-03-10-010116",
+	"4.15.0-0.okd-scos-2024-01-18-000000",
+	"4.15.0-0.okd-2024-02-23-163410",
+	"4.16.0-0.okd-scos-2024-07-20-041305",
+	"4.17.0-okd-scos.ec.1",
+	"4.14.0-0.okd-2024-01-26-175629",
+}
+
+func TestParseOKD(t *testing.T) {
+	var flagtests = []struct {
+		version string
+		stream  string
+		flavor  string
+		build   time.Time
+	}{
+		{"4.15.0-0.okd-2024-03-10-010116", "4.15", FlavorFCOS, time.Date(2024, 3, 10, 1, 1, 16, 0, time.UTC)},
+		{"4.16.0-0.okd-scos-2024-07-20-041305", "4.16", FlavorSCOS, time.Date(2024, 7, 20, 4, 13, 5, 0, time.UTC)},
+		{"4.17.0-okd-scos.ec.1", "4.17", FlavorSCOS, time.Time{}},
+	}
+
+	for _, tt := range flagtests {
+		okd, ok := ParseOKD(version.Must(version.NewVersion(tt.version)))
+
+		require.True(t, ok, tt.version)
+		assert.Equal(t, tt.stream, okd.Stream, tt.version)
+		assert.Equal(t, tt.flavor, okd.Flavor, tt.version)
+		assert.Equal(t, tt.build, okd.Build, tt.version)
+	}
+
+	_, ok := ParseOKD(version.Must(version.NewVersion("1.30.0-rc.1")))
+	assert.False(t, ok)
+}
+
+func TestSortOKDReleases(t *testing.T) {
+	var candidates []*version.Version
+
+	for _, v := range okdVersions {
+		candidates = append(candidates, version.Must(version.NewVersion(v)))
+	}
+
+	sorted := SortReleases(NewReleases(candidates), 0)
+
+	assert.Equal(t, []string{
+		"4.17.0-okd-scos.ec.1",
+		"4.16.0-0.okd-scos-2024-07-20-041305",
+		"4.15.0-0.okd-2024-03-10-010116",
+		"4.15.0-0.okd-2024-02-23-163410",
+		"4.15.0-0.okd-scos-2024-01-18-000000",
+		"4.14.0-0.okd-2024-01-26-175629",
+	}, releaseStrings(sorted))
+}
+
+func TestFilterOKDReleases(t *testing.T) {
+	var candidates []*version.Version
+
+	for _, v := range okdVersions {
+		candidates = append(candidates, version.Must(version.NewVersion(v)))
+	}
+
+	releases := NewReleases(candidates)
+
+	inStream, err := FilterStream(releases, "4.15")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"4.15.0-0.okd-2024-03-10-010116",
+		"4.15.0-0.okd-scos-2024-01-18-000000",
+		"4.15.0-0.okd-2024-02-23-163410",
+	}, releaseStrings(inStream))
+
+	scos, err := FilterFlavor(releases, "scos")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"4.15.0-0.okd-scos-2024-01-18-000000",
+		"4.16.0-0.okd-scos-2024-07-20-041305",
+		"4.17.0-okd-scos.ec.1",
+	}, releaseStrings(scos))
+
+	_, err = FilterStream(releases, "4")
+	assert.Error(t, err)
+
+	_, err = FilterFlavor(releases, "rhcos")
+	assert.Error(t, err)
+}
+
+func TestResolveOKDStream(t *testing.T) {
+	var candidates []*version.Version
+
+	for _, v := range okdVersions {
+		candidates = append(candidates, version.Must(version.NewVersion(v)))
+	}
+
+	var flagtests = []struct {
+		expr     string
+		expected string
+	}{
+		{"4.15", "4.15.0-0.okd-2024-03-10-010116"},
+		{"4.14", "4.14.0-0.okd-2024-01-26-175629"},
+		{"latest", "4.16.0-0.okd-scos-2024-07-20-041305"},
+		{">= 4.14, < 4.16", "4.15.0-0.okd-2024-03-10-010116"},
+		{"stable", "4.16.0-0.okd-scos-2024-07-20-041305"},
+	}
+
+	for _, tt := range flagtests {
+		resolved, err := Resolve(tt.expr, candidates)
+
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.expected, resolved.Original(), tt.expr)
+	}
+
+	_, err := Resolve("4.17", candidates)
+	assert.Error(t, err, "early candidates aren't stable")
+}
@@ -0,0 +1,97 @@
+package versions
+
+import (
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+)
+
+// pages iterates over the pages of a paginated API from first on, following
+// the rel="next" links of their Link headers until a page has none. Servers
+// that don't send Link headers at all have their pages numbered with numbered,
+// when it's not nil, and the caller stops at the last one. The bodies of the
+// responses are closed once yielded.
+func pages(
+	client *retryablehttp.Client, first string, numbered func(page int) string,
+) iter.Seq2[*http.Response, error] {
+	return func(yield func(*http.Response, error) bool) {
+		seen := map[string]bool{}
+		next := first
+
+		for page := 1; next != "" && page <= maxPages && !seen[next]; page++ {
+			seen[next] = true
+
+			logging.Debug("fetching page", "url", next)
+
+			resp, err := client.Get(next)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			next = nextPage(resp)
+			if next == "" && numbered != nil && resp.Header.Get("Link") == "" {
+				next = numbered(page + 1)
+			}
+
+			ok := yield(resp, nil)
+			resp.Body.Close()
+
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// nextPage returns the url of the page after resp, or an empty string when
+// it's the last one.
+func nextPage(resp *http.Response) string {
+	link, ok := findLink(resp, "next")
+	if !ok {
+		return ""
+	}
+
+	next, err := resp.Request.URL.Parse(link.URL)
+	if err != nil {
+		logging.Debug("ignoring invalid next page", "url", link.URL, "error", err)
+		return ""
+	}
+
+	return next.String()
+}
+
+// lastPage returns the number of the last page, when resp links to it.
+func lastPage(resp *http.Response) (int, bool) {
+	link, ok := findLink(resp, "last")
+	if !ok {
+		return 0, false
+	}
+
+	last, err := url.Parse(link.URL)
+	if err != nil {
+		return 0, false
+	}
+
+	page, err := strconv.Atoi(last.Query().Get("page"))
+	if err != nil {
+		return 0, false
+	}
+
+	return page, true
+}
+
+func findLink(resp *http.Response, rel string) (helpers.Link, bool) {
+	links, err := helpers.ParseLinks(resp.Header.Get("Link"))
+	if err != nil {
+		logging.Debug("ignoring invalid Link header", "error", err)
+		return helpers.Link{}, false
+	}
+
+	return links.Rel(rel)
+}
@@ -0,0 +1,120 @@
+package versions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// linkServer serves a release per page, linking to the others with link.
+func linkServer(t *testing.T, requests *int32, link func(host string, page int) string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+
+		if header := link(req.Host, page); header != "" {
+			rw.Header().Set("Link", header)
+		}
+
+		fmt.Fprintf(rw, `[{"tag_name": "v1.%d.0"}]`, page)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGitHubReleasesLinks(t *testing.T) {
+	var flagtests = []struct {
+		testName string
+		link     func(host string, page int) string
+		expected []string
+	}{
+		{
+			"no link header",
+			func(string, int) string { return "" },
+			[]string{"1.1.0"},
+		},
+		{
+			"last page first",
+			func(host string, page int) string {
+				if page > 1 {
+					return fmt.Sprintf(`<http://%s/?page=1>; rel="first"`, host)
+				}
+
+				return fmt.Sprintf(`<http://%s/?page=3>; rel="last", <http://%s/?page=2>; rel="next"`, host, host)
+			},
+			[]string{"1.1.0", "1.2.0", "1.3.0"},
+		},
+		{
+			"only next links, relative",
+			func(_ string, page int) string {
+				if page == 3 {
+					return `</?page=2>; rel="prev"`
+				}
+
+				return fmt.Sprintf(`</?page=%d>; rel=next`, page+1)
+			},
+			[]string{"1.1.0", "1.2.0", "1.3.0"},
+		},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+			var requests int32
+
+			server := linkServer(t, &requests, tt.link)
+
+			releases, err := (&GitHub{Endpoint: server.URL + "/?page="}).Releases()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, releaseStrings(releases))
+			assert.Equal(t, int32(len(tt.expected)), atomic.LoadInt32(&requests), "no page is requested twice")
+		})
+	}
+}
+
+func TestPagesLoop(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var requests int32
+
+	// Every page links back to the first one
+	server := linkServer(t, &requests, func(host string, _ int) string {
+		return fmt.Sprintf(`<http://%s/?page=1>; rel="next"`, host)
+	})
+
+	releases, err := (&GitHub{Endpoint: server.URL + "/?page="}).Releases()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.1.0"}, releaseStrings(releases))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestPagedSourcesLinks(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var requests int32
+
+	// Gitea links to the next page, and the last one links to none
+	server := linkServer(t, &requests, func(host string, page int) string {
+		if page == 2 {
+			return fmt.Sprintf(`<http://%s/?limit=1&page=1>; rel="first"`, host)
+		}
+
+		return fmt.Sprintf(`<http://%s/?limit=1&page=2>; rel="next"`, host)
+	})
+
+	source, err := NewSource(SourceGitea, server.URL+"/?limit=1&page=", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.1.0", "v1.2.0"}, versionStrings(t, source))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,278 @@
+package versions
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+)
+
+// The stability channels releases are listed by, each one including the
+// releases of the ones before it.
+const (
+	StabilityStable = "stable"
+	StabilityRC     = "rc"
+	StabilityBeta   = "beta"
+	StabilityAlpha  = "alpha"
+	StabilityAll    = "all"
+)
+
+// DefaultLimit is how many versions are listed unless all are asked for.
+const DefaultLimit = 20
+
+// stabilities are the stability channels from the most to the least stable.
+var stabilities = []string{StabilityStable, StabilityRC, StabilityBeta, StabilityAlpha}
+
+// unstableRegexp matches the prerelease identifiers of versions that aren't
+// stable, like rc.1 or beta.2, or the early candidates of OKD.
+var unstableRegexp = regexp.MustCompile(`^(alpha|beta|rc|ec)\d*$`)
+
+// Release is a published version, with what its source says about it.
+type Release struct {
+	Version *version.Version
+	// Tag is the tag the release was published with, like v3.15.0 or
+	// kustomize/v5.4.1
+	Tag string
+	// Prerelease and Draft are the flags of the release in GitHub or Gitea
+	Prerelease bool
+	Draft      bool
+	// Published is when it was released, zero if the source doesn't say
+	Published time.Time
+	// Notes are the release notes, in markdown, if the source has them. They're
+	// not cached with the list of versions.
+	Notes string
+}
+
+// ReleaseSource is implemented by the sources that know more of their
+// releases than the version, like if they're drafts or prereleases.
+type ReleaseSource interface {
+	Releases() ([]*Release, error)
+}
+
+// GetReleases returns the releases of source, asking for them if it's a
+// ReleaseSource and making them from its versions otherwise.
+func GetReleases(source VersionSource) ([]*Release, error) {
+	if rs, ok := source.(ReleaseSource); ok {
+		return rs.Releases()
+	}
+
+	versions, err := source.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReleases(versions), nil
+}
+
+// NewReleases returns the releases of versions, with no flags set.
+func NewReleases(versions []*version.Version) []*Release {
+	releases := make([]*Release, 0, len(versions))
+
+	for _, v := range versions {
+		releases = append(releases, &Release{Version: v, Tag: v.Original()})
+	}
+
+	return releases
+}
+
+// ReleaseVersions returns the versions of releases.
+func ReleaseVersions(releases []*Release) []*version.Version {
+	versions := make([]*version.Version, 0, len(releases))
+
+	for _, release := range releases {
+		versions = append(versions, release.Version)
+	}
+
+	return versions
+}
+
+// Stability returns the stability channel of the release, from its prerelease
+// identifiers: 1.30.0-rc.1 is rc, and the early candidates of OKD beta. The
+// other prereleases, like 1.30.0-dev or 2.0.0-pre, and the releases flagged
+// as such upstream are rc too, but the builds of OKD, which are stable.
+func (r *Release) Stability() string {
+	identifiers := strings.FieldsFunc(r.Version.Prerelease(), func(r rune) bool { return r == '.' || r == '-' })
+
+	for _, identifier := range identifiers {
+		match := unstableRegexp.FindStringSubmatch(identifier)
+		if match == nil {
+			continue
+		}
+
+		if match[1] == "ec" {
+			return StabilityBeta
+		}
+
+		return match[1]
+	}
+
+	if _, okd := ParseOKD(r.Version); (r.Prerelease || r.Version.Prerelease() != "") && !okd {
+		return StabilityRC
+	}
+
+	return StabilityStable
+}
+
+// ValidStability tells if channel is one of the stability channels.
+func ValidStability(channel string) error {
+	if channel == StabilityAll {
+		return nil
+	}
+
+	for _, stability := range stabilities {
+		if channel == stability {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid channel %q, must be one of stable, rc, beta, alpha or all", channel)
+}
+
+// FilterReleases returns the releases in the stability channel, keeping their
+// order. Drafts are never listed, they can't be downloaded.
+func FilterReleases(releases []*Release, channel string) ([]*Release, error) {
+	if err := ValidStability(channel); err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*Release, 0, len(releases))
+
+	for _, release := range releases {
+		if release.Draft || !inChannel(release.Stability(), channel) {
+			continue
+		}
+
+		filtered = append(filtered, release)
+	}
+
+	return filtered, nil
+}
+
+func inChannel(stability string, channel string) bool {
+	if channel == StabilityAll {
+		return true
+	}
+
+	for _, s := range stabilities {
+		if s == stability {
+			return true
+		}
+
+		if s == channel {
+			return false
+		}
+	}
+
+	return false
+}
+
+// Stream returns the minor v belongs to, like 4.15 for 4.15.0-0.okd-2024-03-10-010116.
+func Stream(v *version.Version) string {
+	return fmt.Sprintf("%d.%d", v.Segments()[0], v.Segments()[1])
+}
+
+// FilterStream returns the releases in the stream, or minor, like 1.28 or
+// 4.15, keeping their order.
+func FilterStream(releases []*Release, stream string) ([]*Release, error) {
+	want, err := version.NewVersion(stream)
+	if err != nil || strings.Count(strings.TrimPrefix(stream, "v"), ".") != 1 {
+		return nil, fmt.Errorf("invalid stream %q, must be a minor like 4.15", stream)
+	}
+
+	filtered := make([]*Release, 0, len(releases))
+
+	for _, release := range releases {
+		if Stream(release.Version) == Stream(want) {
+			filtered = append(filtered, release)
+		}
+	}
+
+	return filtered, nil
+}
+
+// LatestPerMinor returns the newest release of every minor, keeping their
+// order.
+func LatestPerMinor(releases []*Release) []*Release {
+	latest := map[string]*Release{}
+
+	for _, release := range releases {
+		stream := Stream(release.Version)
+		if current, ok := latest[stream]; !ok || Newer(release.Version, current.Version) {
+			latest[stream] = release
+		}
+	}
+
+	filtered := make([]*Release, 0, len(latest))
+
+	for _, release := range releases {
+		if latest[Stream(release.Version)] == release {
+			filtered = append(filtered, release)
+		}
+	}
+
+	return filtered
+}
+
+// ReleasesBetween returns the releases after from and up to to, like the
+// commits of git's from..to, keeping their order.
+func ReleasesBetween(releases []*Release, from *version.Version, to *version.Version) []*Release {
+	between := make([]*Release, 0, len(releases))
+
+	for _, release := range releases {
+		if Newer(release.Version, from) && !Newer(release.Version, to) {
+			between = append(between, release)
+		}
+	}
+
+	return between
+}
+
+// ContainsRelease tells if release is one of releases.
+func ContainsRelease(releases []*Release, release *Release) bool {
+	for _, r := range releases {
+		if r == release {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SortReleases returns releases from the newest to the oldest, the first
+// limit of them if it's over 0. releases is left as is.
+func SortReleases(releases []*Release, limit int) []*Release {
+	sorted := make([]*Release, len(releases))
+	copy(sorted, releases)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return Newer(sorted[i].Version, sorted[j].Version)
+	})
+
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+
+	return sorted
+}
+
+// Releases lists the releases of the first of the sources that works.
+func (s Fallback) Releases() ([]*Release, error) {
+	var err error
+
+	for _, source := range s {
+		var releases []*Release
+
+		releases, err = GetReleases(source)
+		if err == nil {
+			return releases, nil
+		}
+
+		logging.Debug("versions source failed, trying the next one", "source", fmt.Sprintf("%T", source), "error", err)
+	}
+
+	return nil, err
+}
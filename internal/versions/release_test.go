@@ -0,0 +1,181 @@
+package versions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func release(v string, prerelease bool, draft bool) *Release {
+	return &Release{Version: version.Must(version.NewVersion(v)), Prerelease: prerelease, Draft: draft}
+}
+
+func releaseStrings(releases []*Release) []string {
+	result := make([]string, 0, len(releases))
+	for _, r := range releases {
+		result = append(result, r.Version.String())
+	}
+
+	return result
+}
+
+func TestReleaseStability(t *testing.T) {
+	var flagtests = []struct {
+		release  *Release
+		expected string
+	}{
+		{release("1.30.2", false, false), StabilityStable},
+		{release("1.30.2", true, false), StabilityRC},
+		{release("1.31.0-rc.1", true, false), StabilityRC},
+		{release("1.31.0-beta.0", false, false), StabilityBeta},
+		{release("3.0.0-alpha1", false, false), StabilityAlpha},
+		{release("1.30.3+corp.1", false, false), StabilityStable},
+		{release("3.16.0-dev", true, false), StabilityRC},
+		{release("2.1.0-nightly.20240701", true, false), StabilityRC},
+		// Prereleases are such even if they aren't flagged upstream
+		{release("3.16.0-dev", false, false), StabilityRC},
+		{release("1.0.0-preview.1", false, false), StabilityRC},
+		{release("2.0.0-pre", false, false), StabilityRC},
+		{release("1.2.3-snapshot", false, false), StabilityRC},
+		{release("4.15.0-0.okd-2024-03-10-010116", false, false), StabilityStable},
+		{release("4.15.0-0.okd-2024-03-10-010116", true, false), StabilityStable},
+		{release("4.16.0-0.okd-scos-2024-07-20-041305", true, false), StabilityStable},
+		{release("4.17.0-okd-scos.ec.1", true, false), StabilityBeta},
+	}
+
+	for _, tt := range flagtests {
+		assert.Equal(t, tt.expected, tt.release.Stability(), tt.release.Version.Original())
+	}
+}
+
+func TestFilterReleases(t *testing.T) {
+	releases := []*Release{
+		release("2.0.0-alpha.1", true, false),
+		release("1.31.0-beta.1", true, false),
+		release("1.30.3-rc.0", true, false),
+		release("1.30.2", false, false),
+		release("1.30.4", false, true),
+		release("1.30.1", true, false),
+		release("1.30.5-dev", true, false),
+		release("1.30.6-dev", false, false),
+	}
+
+	var flagtests = []struct {
+		channel  string
+		expected []string
+	}{
+		{StabilityStable, []string{"1.30.2"}},
+		{StabilityRC, []string{"1.30.3-rc.0", "1.30.2", "1.30.1", "1.30.5-dev", "1.30.6-dev"}},
+		{StabilityBeta, []string{"1.31.0-beta.1", "1.30.3-rc.0", "1.30.2", "1.30.1", "1.30.5-dev", "1.30.6-dev"}},
+		{
+			StabilityAlpha,
+			[]string{"2.0.0-alpha.1", "1.31.0-beta.1", "1.30.3-rc.0", "1.30.2", "1.30.1", "1.30.5-dev", "1.30.6-dev"},
+		},
+		{
+			StabilityAll,
+			[]string{"2.0.0-alpha.1", "1.31.0-beta.1", "1.30.3-rc.0", "1.30.2", "1.30.1", "1.30.5-dev", "1.30.6-dev"},
+		},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.channel, func(t *testing.T) {
+			filtered, err := FilterReleases(releases, tt.channel)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, releaseStrings(filtered))
+		})
+	}
+
+	_, err := FilterReleases(releases, "nightly")
+	assert.Error(t, err)
+}
+
+func TestSortReleases(t *testing.T) {
+	releases := []*Release{release("1.29.0", false, false), release("1.30.1", false, false), release("1.30.0", false, false)}
+
+	assert.Equal(t, []string{"1.30.1", "1.30.0"}, releaseStrings(SortReleases(releases, 2)))
+	assert.Equal(t, []string{"1.30.1", "1.30.0", "1.29.0"}, releaseStrings(SortReleases(releases, 0)))
+	assert.Equal(t, "1.29.0", releases[0].Version.String(), "the input must be left as is")
+}
+
+func TestGitHubReleasesFlags(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`[
+			{"tag_name": "v3.16.0", "prerelease": false, "draft": true},
+			{"tag_name": "v3.15.0-rc.1", "prerelease": true, "draft": false},
+			{"tag_name": "v3.14.4", "prerelease": false, "draft": false, "published_at": "2024-04-10T12:00:00Z"}
+		]`))
+	}))
+	defer server.Close()
+
+	source, err := NewSource(SourceGitHub, server.URL+"/?page=", nil)
+	require.NoError(t, err)
+
+	releases, err := GetReleases(source)
+	require.NoError(t, err)
+	require.Len(t, releases, 3)
+	assert.True(t, releases[0].Draft)
+	assert.True(t, releases[1].Prerelease)
+	assert.True(t, releases[0].Published.IsZero())
+	assert.Equal(t, time.Date(2024, 4, 10, 12, 0, 0, 0, time.UTC), releases[2].Published)
+
+	stable, err := FilterReleases(releases, StabilityStable)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3.14.4"}, releaseStrings(stable))
+}
+
+func TestLatestPerMinor(t *testing.T) {
+	releases := []*Release{
+		release("1.30.1", false, false),
+		release("1.29.6", false, false),
+		release("1.30.2", false, false),
+		release("1.28.0", false, false),
+		release("1.29.5", false, false),
+		release("1.31.0-rc.1", true, false),
+	}
+
+	assert.Equal(t, []string{"1.29.6", "1.30.2", "1.28.0", "1.31.0-rc.1"}, releaseStrings(LatestPerMinor(releases)))
+
+	inMinor, err := FilterStream(releases, "1.29")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.29.6", "1.29.5"}, releaseStrings(inMinor))
+}
+
+func TestReleasesBetween(t *testing.T) {
+	releases := []*Release{
+		release("1.30.1", false, false),
+		release("1.30.0", false, false),
+		release("1.29.0", false, false),
+		release("1.28.4", false, false),
+		release("1.28.3", false, false),
+	}
+
+	between := ReleasesBetween(releases, releases[3].Version, releases[0].Version)
+	assert.Equal(t, []string{"1.30.1", "1.30.0", "1.29.0"}, releaseStrings(between))
+	assert.True(t, ContainsRelease(between, releases[0]))
+	assert.False(t, ContainsRelease(between, releases[3]))
+}
+
+func TestReleaseNotes(t *testing.T) {
+	releases, err := processPage(strings.NewReader(`[
+		{"tag_name": "v3.15.0", "body": "## Notable changes"},
+		{"tag_name": "v1.2.0", "description": "From GitLab"}
+	]`), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "## Notable changes", releases[0].Notes)
+	assert.Equal(t, "From GitLab", releases[1].Notes)
+}
@@ -0,0 +1,233 @@
+package versions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+)
+
+// The kinds of version sources, set with versionsSource in the configuration.
+const (
+	SourceGitHub = "github"
+	SourceGitLab = "gitlab"
+	SourceGitea  = "gitea"
+	SourceIndex  = "index"
+)
+
+// maxPages stops paging through servers that ignore the page parameter.
+const maxPages = 100
+
+// VersionSource lists the versions published of a tool.
+type VersionSource interface {
+	Versions() ([]*version.Version, error)
+}
+
+// NewSource returns the source of the given kind reading from endpoint, taking
+// the versions from the tags as told by tags. An empty kind is GitHub.
+func NewSource(kind string, endpoint string, tags *Tags) (VersionSource, error) {
+	switch kind {
+	case "", SourceGitHub:
+		return &GitHub{endpoint, tags}, nil
+	case SourceGitLab:
+		return &GitLab{endpoint, tags}, nil
+	case SourceGitea:
+		return &Gitea{endpoint, tags}, nil
+	case SourceIndex:
+		return &Index{endpoint, tags}, nil
+	default:
+		return nil, fmt.Errorf("unknown versions source %q, must be one of github, gitlab, gitea or index", kind)
+	}
+}
+
+// GitHub lists the releases of a GitHub repository, endpoint being like
+// https://api.github.com/repos/helm/helm/releases?per_page=100&page=
+type GitHub struct {
+	Endpoint string
+	Tags     *Tags
+}
+
+func (s *GitHub) Versions() ([]*version.Version, error) {
+	releases, err := getRemoteReleases(s.Endpoint, s.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReleaseVersions(releases), nil
+}
+
+func (s *GitHub) Releases() ([]*Release, error) {
+	return getRemoteReleases(s.Endpoint, s.Tags)
+}
+
+// GitLab lists the releases of a GitLab project, endpoint being like
+// https://gitlab.example.com/api/v4/projects/42/releases?per_page=100&page=
+type GitLab struct {
+	Endpoint string
+	Tags     *Tags
+}
+
+func (s *GitLab) Versions() ([]*version.Version, error) {
+	releases, err := getPages(s.Endpoint, s.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReleaseVersions(releases), nil
+}
+
+func (s *GitLab) Releases() ([]*Release, error) {
+	return getPages(s.Endpoint, s.Tags)
+}
+
+// Gitea lists the releases of a Gitea or Forgejo repository, endpoint being like
+// https://gitea.example.com/api/v1/repos/helm/helm/releases?limit=50&page=
+type Gitea struct {
+	Endpoint string
+	Tags     *Tags
+}
+
+func (s *Gitea) Versions() ([]*version.Version, error) {
+	releases, err := getPages(s.Endpoint, s.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReleaseVersions(releases), nil
+}
+
+func (s *Gitea) Releases() ([]*Release, error) {
+	return getPages(s.Endpoint, s.Tags)
+}
+
+// Index lists the versions in a static file, either a JSON array of versions,
+// or of objects with a version or tag_name field, or a version per line.
+type Index struct {
+	Endpoint string
+	Tags     *Tags
+}
+
+func (s *Index) Versions() ([]*version.Version, error) {
+	logging.Debug("fetching versions index", "endpoint", s.Endpoint)
+
+	resp, err := newListClient("").Get(s.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request to %s failed with %s", s.Endpoint, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseIndex(data, s.Tags)
+}
+
+func parseIndex(data []byte, filter *Tags) ([]*version.Version, error) {
+	var (
+		tags    []string
+		objects []struct {
+			Version string `json:"version"`
+			Tag     string `json:"tag_name"`
+		}
+	)
+
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.HasPrefix(data, []byte("[")) && json.Unmarshal(data, &tags) == nil:
+	case bytes.HasPrefix(data, []byte("[")):
+		tags = nil
+
+		if err := json.Unmarshal(data, &objects); err != nil {
+			return nil, err
+		}
+
+		for _, object := range objects {
+			if object.Version != "" {
+				tags = append(tags, object.Version)
+			} else {
+				tags = append(tags, object.Tag)
+			}
+		}
+	default:
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				tags = append(tags, line)
+			}
+		}
+	}
+
+	versions := make([]*version.Version, 0, len(tags))
+
+	for _, tag := range tags {
+		if v, ok := filter.Parse(tag); ok {
+			versions = append(versions, v)
+		}
+	}
+
+	return versions, nil
+}
+
+// getPages pages through the releases of GitLab or Gitea, following the links
+// to the next pages, or appending the page number to endpoint when the server
+// doesn't send them. It stops at the last page, the first empty one, or before
+// when GitLab says there's no next page.
+func getPages(endpoint string, tags *Tags) ([]*Release, error) {
+	var (
+		releases []*Release
+		first    string
+	)
+
+	numbered := func(page int) string {
+		return endpoint + strconv.Itoa(page)
+	}
+
+	for resp, err := range pages(newListClient(""), numbered(1), numbered) {
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("request to %s failed with %s", endpoint, resp.Status)
+		}
+
+		page, err := readPage(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		// Servers ignoring the page parameter return the same page forever.
+		// Pages are compared by their tags, as they may have no versions of
+		// the tool.
+		if len(page) == 0 || page[0].Release == first {
+			break
+		}
+
+		first = page[0].Release
+		releases = append(releases, pageReleases(page, tags)...)
+
+		if next, ok := resp.Header["X-Next-Page"]; ok && (len(next) == 0 || next[0] == "") {
+			break
+		}
+	}
+
+	logging.Debug("Found releases", "count", len(releases))
+
+	return releases, nil
+}
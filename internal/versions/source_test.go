@@ -0,0 +1,102 @@
+package versions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func versionStrings(t *testing.T, source VersionSource) []string {
+	t.Helper()
+
+	versionList, err := source.Versions()
+	require.NoError(t, err)
+
+	result := make([]string, 0, len(versionList))
+	for _, v := range versionList {
+		result = append(result, v.Original())
+	}
+
+	return result
+}
+
+func TestPagedSources(t *testing.T) {
+	var pages = [][]string{{"v3.15.1", "v3.15.0"}, {"v3.14.4"}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+
+		// GitLab says which is the next page, Gitea returns an empty one
+		if req.URL.Path == "/gitlab" {
+			next := ""
+			if page < len(pages) {
+				next = strconv.Itoa(page + 1)
+			}
+
+			rw.Header().Set("X-Next-Page", next)
+		}
+
+		fmt.Fprint(rw, "[")
+
+		if page <= len(pages) {
+			for i, tag := range pages[page-1] {
+				if i > 0 {
+					fmt.Fprint(rw, ",")
+				}
+
+				fmt.Fprintf(rw, `{"tag_name": %q}`, tag)
+			}
+		}
+
+		fmt.Fprint(rw, "]")
+	}))
+	defer server.Close()
+
+	expected := []string{"v3.15.1", "v3.15.0", "v3.14.4"}
+
+	source, err := NewSource(SourceGitLab, server.URL+"/gitlab?per_page=100&page=", nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, versionStrings(t, source))
+
+	source, err = NewSource(SourceGitea, server.URL+"/gitea?limit=50&page=", nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, versionStrings(t, source))
+
+	// Servers that ignore the page parameter
+	source, err = NewSource(SourceGitea, server.URL+"/gitea?page=1&ignored=", nil)
+	require.NoError(t, err)
+	assert.Equal(t, pages[0], versionStrings(t, source))
+}
+
+func TestIndexSource(t *testing.T) {
+	var flagtests = []struct {
+		testName string
+		content  string
+	}{
+		{"json versions", `["1.30.1", "v1.29.6"]`},
+		{"json objects", `[{"version": "1.30.1"}, {"tag_name": "v1.29.6"}]`},
+		{"lines", "# kubectl versions\n1.30.1\n\nv1.29.6\n"},
+		{"tags that aren't versions", `["1.30.1", "helm-chart-4.0.1", "latest", "v1.29.6"]`},
+	}
+
+	for _, tt := range flagtests {
+		t.Run(tt.testName, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(rw, tt.content)
+			}))
+			defer server.Close()
+
+			source, err := NewSource(SourceIndex, server.URL+"/versions", nil)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"1.30.1", "v1.29.6"}, versionStrings(t, source))
+		})
+	}
+
+	_, err := NewSource("svn", "", nil)
+	require.Error(t, err)
+}
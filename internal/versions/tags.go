@@ -0,0 +1,77 @@
+package versions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+)
+
+// Tags tells which tags of a source are versions of the tool, for repositories
+// that tag more than its releases, like kustomize/v5.4.1 next to api/v0.17.2.
+// A nil Tags takes every tag that is a version.
+type Tags struct {
+	// Prefix is stripped from the tags, the ones without it are skipped
+	Prefix string
+	// Pattern must match the tags, once stripped of Prefix. When it has a
+	// group, the version is what the first one captures.
+	Pattern *regexp.Regexp
+}
+
+// NewTags returns the tags with the prefix and the pattern, or nil when both
+// are empty.
+func NewTags(prefix string, pattern string) (*Tags, error) {
+	if prefix == "" && pattern == "" {
+		return nil, nil // nolint: nilnil
+	}
+
+	tags := &Tags{Prefix: prefix}
+
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag pattern %q: %w", pattern, err)
+		}
+
+		tags.Pattern = re
+	}
+
+	return tags, nil
+}
+
+// Parse returns the version tag is, if it's one of the tool. The tags that
+// aren't are logged and skipped, so an odd tag never breaks the listing.
+func (t *Tags) Parse(tag string) (*version.Version, bool) {
+	name := tag
+
+	if t != nil && t.Prefix != "" {
+		if !strings.HasPrefix(name, t.Prefix) {
+			logging.Debug("skipping tag without the prefix", "tag", tag, "prefix", t.Prefix)
+			return nil, false
+		}
+
+		name = strings.TrimPrefix(name, t.Prefix)
+	}
+
+	if t != nil && t.Pattern != nil {
+		match := t.Pattern.FindStringSubmatch(name)
+		if match == nil {
+			logging.Debug("skipping tag not matching the pattern", "tag", tag, "pattern", t.Pattern)
+			return nil, false
+		}
+
+		if len(match) > 1 {
+			name = match[1]
+		}
+	}
+
+	v, err := version.NewVersion(name)
+	if err != nil {
+		logging.Debug("skipping tag that isn't a version", "tag", tag, "error", err)
+		return nil, false
+	}
+
+	return v, true
+}
@@ -0,0 +1,96 @@
+package versions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagsParse(t *testing.T) {
+	kustomize, err := NewTags("kustomize/", "")
+	require.NoError(t, err)
+
+	pattern, err := NewTags("", `^release-(\d+\.\d+\.\d+)$`)
+	require.NoError(t, err)
+
+	var flagtests = []struct {
+		tags     *Tags
+		tag      string
+		expected string
+	}{
+		{nil, "v3.15.0", "v3.15.0"},
+		{nil, "helm-chart-4.0.1", ""},
+		{kustomize, "kustomize/v5.4.1", "v5.4.1"},
+		{kustomize, "api/v0.17.2", ""},
+		{kustomize, "v5.4.1", ""},
+		{kustomize, "kustomize/latest", ""},
+		{pattern, "release-1.2.3", "1.2.3"},
+		{pattern, "release-1.2", ""},
+		{pattern, "1.2.3", ""},
+	}
+
+	for _, tt := range flagtests {
+		v, ok := tt.tags.Parse(tt.tag)
+
+		if tt.expected == "" {
+			assert.False(t, ok, tt.tag)
+			continue
+		}
+
+		require.True(t, ok, tt.tag)
+		assert.Equal(t, tt.expected, v.Original(), tt.tag)
+	}
+
+	tags, err := NewTags("", "")
+	require.NoError(t, err)
+	assert.Nil(t, tags)
+
+	_, err = NewTags("", "v(")
+	assert.Error(t, err)
+}
+
+func TestTaggedSources(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// The first page has no release of kustomize, but it's not the last one
+	var pages = [][]string{
+		{"api/v0.17.2", "cmd/config/v0.14.1"},
+		{"kustomize/v5.4.1", "kyaml/v0.17.1", "kustomize/v5.4.0"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+
+		fmt.Fprint(rw, "[")
+
+		if page <= len(pages) {
+			for i, tag := range pages[page-1] {
+				if i > 0 {
+					fmt.Fprint(rw, ",")
+				}
+
+				fmt.Fprintf(rw, `{"tag_name": %q}`, tag)
+			}
+		}
+
+		fmt.Fprint(rw, "]")
+	}))
+	defer server.Close()
+
+	tags, err := NewTags("kustomize/", "")
+	require.NoError(t, err)
+
+	source, err := NewSource(SourceGitea, server.URL+"/?page=", tags)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v5.4.1", "v5.4.0"}, versionStrings(t, source))
+
+	// Without the prefix none of the tags is a version, which isn't an error
+	source, err = NewSource(SourceGitea, server.URL+"/?page=", nil)
+	require.NoError(t, err)
+	assert.Empty(t, versionStrings(t, source))
+}
@@ -8,63 +8,63 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"runtime"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/hashicorp/go-version"
-	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers" // nolint:staticcheck
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
 	"github.com/mitchellh/go-homedir"
 )
 
 type Page struct {
-	Release string `json:"tag_name"`
+	Release    string  `json:"tag_name"`
+	Assets     []Asset `json:"assets"`
+	Prerelease bool    `json:"prerelease"`
+	Draft      bool    `json:"draft"`
+	// Upcoming is set by GitLab for the releases not published yet
+	Upcoming    bool      `json:"upcoming_release"`
+	PublishedAt time.Time `json:"published_at"`
+	// ReleasedAt is when GitLab releases were published
+	ReleasedAt time.Time `json:"released_at"`
+	// Body has the release notes, Description in GitLab
+	Body        string `json:"body"`
+	Description string `json:"description"`
 }
 
 const (
 	httpTimeout = 10 * time.Second
 	httpRetries = 3
+	// releaseTimeout is how long looking up the assets of a release may take
+	releaseTimeout = 5 * time.Second
+	// pageWorkers is how many pages of releases are fetched at the same time
+	pageWorkers = 4
 )
 
+// SortVersions returns versions from the newest to the oldest, only the stable
+// ones unless allReleases, and the first DefaultLimit of them unless
+// allVersions. versions is left as is.
 func SortVersions(versions []*version.Version, allReleases bool, allVersions bool) ([]*version.Version, error) {
-	var (
-		numberOfVersion int
-		finalVersions   []*version.Version
-	)
-
-	sort.Sort(sort.Reverse(version.Collection(versions)))
+	channel := StabilityStable
+	if allReleases {
+		channel = StabilityAll
+	}
 
+	limit := DefaultLimit
 	if allVersions {
-		numberOfVersion = len(versions) - 1
-	} else {
-		numberOfVersion = 19
+		limit = 0
 	}
 
-	for i := 0; i <= numberOfVersion; i++ {
-		if i == len(versions) {
-			break
-		}
-
-		if allReleases {
-			finalVersions = append(finalVersions, versions[i])
-		} else {
-			if !strings.ContainsAny(versions[i].String(), "beta") &&
-				!strings.ContainsAny(versions[i].String(), "alpha") &&
-				!strings.ContainsAny(versions[i].String(), "rc") {
-				finalVersions = append(finalVersions, versions[i])
-			} else {
-				versions = append(versions[:i], versions[i+1:]...)
-				i--
-			}
-		}
+	releases, err := FilterReleases(NewReleases(versions), channel)
+	if err != nil {
+		return nil, err
 	}
 
-	return finalVersions, nil
+	return ReleaseVersions(SortReleases(releases, limit)), nil
 }
 
 func PrintVersions(versions []*version.Version) {
@@ -73,6 +73,17 @@ func PrintVersions(versions []*version.Version) {
 	}
 }
 
+// NormalizeLabel validates a custom version label, like 1.30.2+corp.1, and
+// returns it in the canonical form used to name the installed binaries.
+func NormalizeLabel(label string) (string, error) {
+	v, err := version.NewVersion(label)
+	if err != nil {
+		return "", err
+	}
+
+	return v.String(), nil
+}
+
 func GetLocalVersions(binary string) ([]*version.Version, error) {
 	var versions []*version.Version // nolint:prealloc
 
@@ -94,7 +105,8 @@ func GetLocalVersions(binary string) ([]*version.Version, error) {
 
 		ver, err := version.NewVersion(vs)
 		if err != nil {
-			return versions, err
+			logging.Debug("skipping file with an invalid version", "path", match, "error", err)
+			continue
 		}
 
 		versions = append(versions, ver)
@@ -103,101 +115,266 @@ func GetLocalVersions(binary string) ([]*version.Version, error) {
 	return versions, nil
 }
 
-func processPage(body io.Reader) ([]*version.Version, error) {
-	var ( //nolint:prealloc
-		pageVersions []*version.Version
-		rel          []Page
-	)
+// processPage returns the releases of a page whose tags are versions of the
+// tool.
+func processPage(body io.Reader, tags *Tags) ([]*Release, error) {
+	page, err := readPage(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return pageReleases(page, tags), nil
+}
+
+func readPage(body io.Reader) ([]Page, error) {
+	var page []Page
 
 	data, err := io.ReadAll(body)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := json.Unmarshal(data, &rel); err != nil {
+	if err := json.Unmarshal(data, &page); err != nil {
 		return nil, err
 	}
 
-	for _, element := range rel {
-		v, err := version.NewVersion(element.Release)
-		if err != nil {
-			return nil, err
+	return page, nil
+}
+
+// pageReleases returns the releases of page, skipping the ones whose tags
+// aren't versions of the tool.
+func pageReleases(page []Page, tags *Tags) []*Release {
+	releases := make([]*Release, 0, len(page))
+
+	for _, element := range page {
+		v, ok := tags.Parse(element.Release)
+		if !ok {
+			continue
+		}
+
+		published := element.PublishedAt
+		if published.IsZero() {
+			published = element.ReleasedAt
 		}
 
-		pageVersions = append(pageVersions, v)
+		notes := element.Body
+		if notes == "" {
+			notes = element.Description
+		}
+
+		releases = append(releases, &Release{
+			Version:    v,
+			Tag:        element.Release,
+			Prerelease: element.Prerelease,
+			Draft:      element.Draft || element.Upcoming,
+			Published:  published,
+			Notes:      notes,
+		})
 	}
 
-	return pageVersions, nil
+	return releases
 }
 
-func GetRemoteVersions(endpoint string) ([]*version.Version, error) {
-	var versions []*version.Version
-
+// newClient returns the client for the versions endpoints, authenticated with
+// token if set. Only GitHub gets the GITHUB_TOKEN, it must not leak elsewhere.
+func newClient(token string) *retryablehttp.Client {
 	client := retryablehttp.NewClient()
 	client.RetryMax = httpRetries
 	client.HTTPClient.Timeout = httpTimeout
 	client.Backoff = backoffHandler
 	client.CheckRetry = retryPolicy
 	client.HTTPClient.Transport = &AuthRoundTripper{
-		token:            os.Getenv("GITHUB_TOKEN"),
+		token:            token,
 		nextRoundTripper: http.DefaultTransport,
 	}
 
 	client.Logger = logging.L
 
-	// Fetch the first page
-	logging.Debug("fetching first page", "endpoint", endpoint+"1")
+	if noRetries {
+		client.RetryMax = 0
+	}
+
+	return client
+}
+
+// noRetries makes the clients give up at the first failure, without waiting
+// for the rate limits to reset, while an optional list is fetched.
+var noRetries bool
+
+// withoutRetries sets noRetries until the returned function is called.
+func withoutRetries() func() {
+	noRetries = true
+
+	return func() { noRetries = false }
+}
+
+// newListClient returns the client for the lists of versions, whose responses
+// are kept in the cache and asked again with conditional requests.
+func newListClient(token string) *retryablehttp.Client {
+	client := newClient(token)
+	client.HTTPClient.Transport = &AuthRoundTripper{
+		token:            token,
+		nextRoundTripper: &ETagRoundTripper{nextRoundTripper: http.DefaultTransport},
+	}
 
-	resp, err := client.Get(endpoint + "1")
+	return client
+}
+
+// GetRelease returns the release of tag from the GitHub API, given the
+// versions endpoint of the repository. It's asked once, without retries nor
+// waiting for the rate limits to reset, as the caller has a fallback.
+func GetRelease(endpoint string, tag string) (*Page, error) {
+	url, err := releaseURL(endpoint, tag)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusForbidden {
-		fmt.Println("The request to Github's API failed, sorry.")
-		fmt.Println("You may still install the version you want, if you know it. It will always go as X.Y.Z.")
-		fmt.Println("The complete request response is ", resp)
-
-		return nil, errors.New("request to Github's API failed with 403 Forbidden")
+	client := &http.Client{
+		Timeout:   releaseTimeout,
+		Transport: &AuthRoundTripper{token: os.Getenv("GITHUB_TOKEN"), nextRoundTripper: http.DefaultTransport},
 	}
 
-	lastPage, err := helpers.GetLastPage(resp.Header.Get("Link"))
+	logging.Debug("fetching release", "url", url)
+
+	resp, err := client.Get(url)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("release %s not found", tag)
+	default:
+		return nil, fmt.Errorf("request to Github's API failed with %s", resp.Status)
+	}
 
-	logging.Debug("last page determined", "lastPage", lastPage)
+	var page Page
 
-	// Process first page
-	pageVersions, err := processPage(resp.Body)
-	if err != nil {
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 		return nil, err
 	}
 
-	versions = append(versions, pageVersions...)
-	logging.Debug("Processed page\n", "versions", len(pageVersions), "page", 1, "of", lastPage)
+	return &page, nil
+}
+
+// getRemoteReleases returns the releases in the GitHub API whose tags are
+// versions of the tool.
+func getRemoteReleases(endpoint string, tags *Tags) ([]*Release, error) {
+	var (
+		releases []*Release
+		page     int
+	)
 
-	// Process remaining pages
-	for page := 2; page <= lastPage; page++ {
-		logging.Debug("fetching page", "endpoint", endpoint+"1")
+	client := newListClient(os.Getenv("GITHUB_TOKEN"))
+
+	for resp, err := range pages(client, endpoint+"1", nil) {
+		page++
 
-		resp, err := client.Get(endpoint + strconv.Itoa(page))
 		if err != nil {
 			return nil, err
 		}
-		defer resp.Body.Close()
 
-		pageVersions, err := processPage(resp.Body)
+		if resp.StatusCode == http.StatusForbidden {
+			logging.Debug("request to Github's API forbidden", "url", resp.Request.URL, "headers", resp.Header)
+
+			return nil, errors.New("request to Github's API failed with 403 Forbidden, " +
+				"you may still install the version you want if you know it")
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("request to Github's API failed with %s", resp.Status)
+		}
+
+		pageReleases, err := processPage(resp.Body, tags)
 		if err != nil {
 			return nil, err
 		}
 
-		versions = append(versions, pageVersions...)
-		logging.Debug("Processed page", "page", page, "of", lastPage, "with", len(pageVersions))
+		releases = append(releases, pageReleases...)
+		logging.Debug("Processed page", "versions", len(pageReleases), "page", page)
+
+		// When the first page tells which is the last one, the others are
+		// fetched at once instead of one after the other
+		if last, ok := lastPage(resp); ok && page == 1 {
+			logging.Debug("last page determined", "lastPage", last)
+
+			remaining, err := fetchPages(client, endpoint, last, tags)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, pageReleases := range remaining {
+				releases = append(releases, pageReleases...)
+			}
+
+			page = last
+
+			break
+		}
 	}
 
-	logging.Debug("Found releases", "count", len(versions), "over pages", lastPage)
+	logging.Debug("Found releases", "count", len(releases), "over pages", page)
 
-	return versions, nil
+	return releases, nil
+}
+
+// fetchPages fetches the pages 2 to lastPage of endpoint concurrently, and
+// returns their releases in the order of the pages. The rate limits are still
+// respected, every request backing off on its own when they're hit.
+func fetchPages(client *retryablehttp.Client, endpoint string, lastPage int, tags *Tags) ([][]*Release, error) {
+	var (
+		pages     = make([][]*Release, max(lastPage-1, 0))
+		errs      = make([]error, len(pages))
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, pageWorkers)
+	)
+
+	for i := range pages {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			pages[i], errs[i] = fetchPage(client, endpoint, i+2, tags) // nolint: mnd
+		}(i)
+	}
+
+	wg.Wait()
+
+	// The first error by page, so it's the same whatever the timing
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pages, nil
+}
+
+func fetchPage(client *retryablehttp.Client, endpoint string, page int, tags *Tags) ([]*Release, error) {
+	logging.Debug("fetching page", "endpoint", endpoint+strconv.Itoa(page))
+
+	resp, err := client.Get(endpoint + strconv.Itoa(page))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request to Github's API failed with %s", resp.Status)
+	}
+
+	pageReleases, err := processPage(resp.Body, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Debug("Processed page", "page", page, "with", len(pageReleases))
+
+	return pageReleases, nil
 }
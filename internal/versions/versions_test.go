@@ -7,10 +7,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-version"
-	. "github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
 	"github.com/mitchellh/go-homedir"
 	"github.com/stretchr/testify/assert"
@@ -121,6 +123,22 @@ func TestSortVersions(t *testing.T) { // nolint: funlen
 			true,
 			30,
 		},
+		{
+			"build metadata",
+			[]string{"1.30.3+corp.1", "1.30.2", "1.31.0-rc.1", "1.29.0+patched"},
+			[]string{"1.30.3+corp.1", "1.30.2", "1.29.0+patched"},
+			false,
+			false,
+			3,
+		},
+		{
+			"okd builds",
+			[]string{"4.15.0-0.okd-2024-03-10-010116", "4.16.0-0.okd-scos-2024-07-20-041305", "4.17.0-okd-scos.ec.1"},
+			[]string{"4.16.0-0.okd-scos-2024-07-20-041305", "4.15.0-0.okd-2024-03-10-010116"},
+			false,
+			false,
+			2,
+		},
 	}
 
 	for _, tt := range flagtests {
@@ -153,39 +171,14 @@ func TestSortVersions(t *testing.T) { // nolint: funlen
 
 			t.Logf("actualVersionsVrs: %s", actualVersionsVrs)
 			assert.Nil(t, err)
+			assert.Equal(t, tt.input[0], receivedVersionsVrs[0].Original(), "the input must be left as is")
 			assert.Equal(t, expectedVersionsStr, actualVersionsStr)
 			assert.Equal(t, tt.versionsLength, len(actualVersionsStr))
 		})
 	}
 }
 
-func TestGetLastPage(t *testing.T) {
-	var flagtests = []struct {
-		testName string
-		input    string
-		expected int
-	}{
-		{"value 20", "<https://api.github.com/repositories/20580498/releases?per_page=20&page=1>; rel=\"next\", <https://api.github.com/repositories/20580498/releases?per_page=20&page=20>; rel=\"last\"", 20}, // nolint: lll
-		{"value 30", "<https://api.github.com/repositories/20580498/releases?per_page=20&page=1>; rel=\"next\", <https://api.github.com/repositories/20580498/releases?per_page=20&page=30>; rel=\"last\"", 30}, // nolint: lll
-		{"value 2", "<https://api.github.com/repositories/20580498/releases?per_page=20&page=1>; rel=\"next\", <https://api.github.com/repositories/20580498/releases?per_page=20&page=0>; rel=\"last\"", 2},    // nolint: lll
-		{"vaue 1", "<https://api.github.com/repositories/20580498/releases?per_page=20&page=1>; rel=\"next\", <https://api.github.com/repositories/20580498/releases?per_page=20&page=1>; rel=\"last\"", 1},     // nolint: lll
-	}
-
-	for _, tt := range flagtests {
-		tt := tt
-		t.Run(tt.testName, func(t *testing.T) {
-			var err error
-
-			actualLastPage, err := GetLastPage(tt.input)
-			expectedLastPage := tt.expected
-
-			assert.Nil(t, err)
-			assert.Equal(t, expectedLastPage, actualLastPage)
-		})
-	}
-}
-
-func TestGetRemoteVersions(t *testing.T) { // nolint: funlen
+func TestGitHubVersions(t *testing.T) { // nolint: funlen
 	var flagtests = []struct {
 		testName string
 		maxPages string
@@ -243,7 +236,7 @@ func TestGetRemoteVersions(t *testing.T) { // nolint: funlen
 			}))
 			defer server.Close()
 
-			remoteVersions, err := GetRemoteVersions(server.URL + "/?page=")
+			remoteVersions, err := (&GitHub{Endpoint: server.URL + "/?page="}).Versions()
 			t.Logf("remoteVersions: %s", remoteVersions)
 			require.NoError(t, err)
 
@@ -286,6 +279,11 @@ func TestGetLocalVersions(t *testing.T) { // nolint: funlen
 				"1.7.13", "1.8.6", "1.8.7", "1.9.1", "1.9.2",
 			},
 		},
+		{
+			"custom labels",
+			[]string{"1.30.2+corp.1", "1.29.0", "not-a-version"},
+			[]string{"1.29.0", "1.30.2+corp.1"},
+		},
 	}
 
 	for _, tt := range flagtests {
@@ -342,3 +340,71 @@ func TestGetLocalVersions(t *testing.T) { // nolint: funlen
 		})
 	}
 }
+
+func TestGitHubVersionsConcurrent(t *testing.T) {
+	const lastPage = 12
+
+	var (
+		inFlight    int32
+		maxInFlight int32
+		limited     int32
+	)
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		// FailNow can't be called from the goroutines of the server
+		page, err := strconv.Atoi(req.URL.Query().Get("page"))
+		if err != nil {
+			t.Errorf("invalid page requested: %s", req.URL)
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		// The third page is rate limited once, it must be retried and not lost
+		if page == 3 && atomic.CompareAndSwapInt32(&limited, 0, 1) {
+			rw.Header().Set("X-Ratelimit-Remaining", "0")
+			rw.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+			rw.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		// Later pages answer first, so they finish out of order
+		time.Sleep(time.Duration(lastPage-page) * 5 * time.Millisecond)
+
+		rw.Header().Set("Link",
+			fmt.Sprintf(`<http://%s/?page=2>; rel="next", <http://%s/?page=%d>; rel="last"`, req.Host, req.Host, lastPage))
+		_, _ = fmt.Fprintf(rw, `[{"tag_name": "v1.%d.1"}, {"tag_name": "v1.%d.0"}]`, page, page)
+	}))
+	defer server.Close()
+
+	remoteVersions, err := (&GitHub{Endpoint: server.URL + "/?page="}).Versions()
+	require.NoError(t, err)
+
+	expected := make([]string, 0, 2*lastPage)
+	for page := 1; page <= lastPage; page++ {
+		expected = append(expected, fmt.Sprintf("1.%d.1", page), fmt.Sprintf("1.%d.0", page))
+	}
+
+	received := make([]string, 0, len(remoteVersions))
+	for _, v := range remoteVersions {
+		received = append(received, v.String())
+	}
+
+	assert.Equal(t, expected, received)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&limited))
+	assert.Greater(t, atomic.LoadInt32(&maxInFlight), int32(1), "the pages are fetched concurrently")
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(pageWorkers))
+}
@@ -8,10 +8,20 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/hashicorp/go-version"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/config"
 	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/helpers"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/logging"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/provider"
+	"github.com/little-angry-clouds/kubernetes-binaries-managers/internal/versions"
 	"github.com/mitchellh/go-homedir"
 )
 
+// Installer is the command that installs the version the cluster needs in auto
+// mode, or the one a channel points to, install and the version are appended
+// to it. It's the manager of the binary when empty, like kbenv.
+var Installer []string
+
 func Wrapper(binName string) { // nolint: funlen
 	home, _ := homedir.Dir()
 
@@ -64,19 +74,53 @@ func Wrapper(binName string) { // nolint: funlen
 			return
 		}
 
-		bin := fmt.Sprintf("%s/%s-v%s%s", binPath, binName, version, fileExt)
-		bin, _ = filepath.Abs(bin)
+		installMissing(binPath, binName, version, fileExt)
+
+		finalVersion = version
+	} else if versions.IsChannel(finalVersion) {
+		// A channel, like stable-1.29, read from the cached versions or from
+		// upstream and installed when it's missing
+		if logging.L == nil {
+			logging.Setup("error")
+		}
+
+		version, err := resolveChannel(binName, finalVersion)
+		if err != nil {
+			// Upstream can't be reached, so the newest installed version of the
+			// channel is used instead
+			installed, localErr := versions.GetLocalVersions(binName)
+			helpers.CheckGenericError(localErr)
+
+			local, localErr := versions.Resolve(finalVersion, installed)
+			if localErr != nil {
+				fmt.Fprintf(os.Stderr, "Error resolving the %s channel: %s\n", finalVersion, err)
+				os.Exit(1)
+			}
+
+			logging.Debug("using the newest installed version of the channel", "channel", finalVersion, "error", err)
 
-		if !helpers.FileExists(bin) {
-			args := []string{"install", version}
-			cmd := exec.Command("kbenv"+fileExt, args...) // nolint: gosec
-			cmd.Stderr = os.Stderr
-			err = cmd.Run()
+			finalVersion = local.Original()
+		} else {
+			installMissing(binPath, binName, version.String(), fileExt)
 
-			helpers.CheckGenericError(err)
+			finalVersion = version.String()
+		}
+	} else if !versions.IsExact(finalVersion) {
+		// A constraint, like 1.29, resolved to the newest installed match
+		if logging.L == nil {
+			logging.Setup("error")
 		}
 
-		finalVersion = version
+		installed, err := versions.GetLocalVersions(binName)
+		helpers.CheckGenericError(err)
+
+		version, err := versions.Resolve(finalVersion, installed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s, install one with: %s install '%s'\n", err, managerName(binName), finalVersion)
+			os.Exit(1)
+		}
+
+		finalVersion = version.Original()
 	}
 
 	bin := fmt.Sprintf("%s/%s-v%s", binPath, binName, finalVersion)
@@ -95,3 +139,95 @@ func Wrapper(binName string) { // nolint: funlen
 		os.Exit(1)
 	}
 }
+
+// installMissing installs the version of binName with the Installer, unless
+// it's installed already.
+func installMissing(binPath string, binName string, version string, fileExt string) {
+	bin := fmt.Sprintf("%s/%s-v%s%s", binPath, binName, version, fileExt)
+	bin, _ = filepath.Abs(bin)
+
+	if helpers.FileExists(bin) {
+		return
+	}
+
+	installer := Installer
+	if len(installer) == 0 {
+		installer = strings.Fields(managerName(binName))
+		installer[0] += fileExt
+	}
+
+	args := append(append([]string{}, installer[1:]...), "install", version)
+	cmd := exec.Command(installer[0], args...) // nolint: gosec
+	cmd.Stderr = os.Stderr
+
+	helpers.CheckGenericError(cmd.Run())
+}
+
+// resolveChannel returns the version the channel of binName points to.
+func resolveChannel(binName string, channel string) (*version.Version, error) {
+	source, err := versionSource(binName)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions.ResolveChannel(source, channel)
+}
+
+// versionSource returns the source of the versions of binName, configured and
+// cached like the managers do, so both read the same channels.
+func versionSource(binName string) (versions.VersionSource, error) {
+	conf, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := provider.LoadDefinitions(provider.DefinitionsDir()); err != nil {
+		return nil, err
+	}
+
+	defaults, err := provider.Defaults(binName)
+	if err != nil {
+		return nil, err
+	}
+
+	tool, err := conf.Resolve(binName, defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := provider.New(binName, tool)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := p.VersionSource()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := conf.VersionsTTL()
+	if err != nil {
+		return nil, err
+	}
+
+	return &versions.Cached{
+		Source: source,
+		Tool:   binName,
+		Origin: fmt.Sprint(tool),
+		TTL:    ttl,
+	}, nil
+}
+
+// managerName returns the command that manages binName, for the messages.
+func managerName(binName string) string {
+	switch binName {
+	case "kubectl":
+		return "kbenv"
+	case "helm":
+		return "helmenv"
+	case "oc":
+		return "ocenv"
+	default:
+		return "kbm " + binName
+	}
+}